@@ -0,0 +1,277 @@
+{{$svcType := .ServiceType}}
+
+// Fake{{$svcType}}HTTPClient is an in-memory {{$svcType}}HTTPClient for tests.
+// Each method records its arguments, then calls its Stub func when set or
+// returns the values set with its Returns method. A method with neither fails
+// with codes.Unimplemented.
+type Fake{{$svcType}}HTTPClient struct {
+{{- range .MethodSets}}
+	{{- if .ClientStreaming}}
+	{{.Name}}Stub func(ctx context.Context, opts ...option.BinderOption) ({{$svcType}}_{{.Name}}HTTPClient, error)
+	{{- else if .ServerStreaming}}
+	{{.Name}}Stub func(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) ({{$svcType}}_{{.Name}}HTTPClient, error)
+	{{- else}}
+	{{.Name}}Stub func(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+
+	mu sync.Mutex
+{{- range .MethodSets}}
+	{{- if .ClientStreaming}}
+	{{unexport .Name}}Calls []context.Context
+	{{unexport .Name}}Returns *struct{ stream {{$svcType}}_{{.Name}}HTTPClient; err error }
+	{{- else if .ServerStreaming}}
+	{{unexport .Name}}Calls []struct{ ctx context.Context; in *{{.Request}} }
+	{{unexport .Name}}Returns *struct{ stream {{$svcType}}_{{.Name}}HTTPClient; err error }
+	{{- else}}
+	{{unexport .Name}}Calls []struct{ ctx context.Context; in *{{.Request}} }
+	{{unexport .Name}}Returns *struct{ reply *{{.Reply}}; err error }
+	{{- end}}
+{{- end}}
+}
+
+var _ {{$svcType}}HTTPClient = (*Fake{{$svcType}}HTTPClient)(nil)
+
+{{range .MethodSets}}
+{{- $name := .Name}}
+{{- $field := unexport .Name}}
+{{- if .ClientStreaming}}
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}(ctx context.Context, opts ...option.BinderOption) ({{$svcType}}_{{$name}}HTTPClient, error) {
+  f.mu.Lock()
+  f.{{$field}}Calls = append(f.{{$field}}Calls, ctx)
+  stub, ret := f.{{$name}}Stub, f.{{$field}}Returns
+  f.mu.Unlock()
+
+  if stub != nil {
+    return stub(ctx, opts...)
+  }
+  if ret != nil {
+    return ret.stream, ret.err
+  }
+  return nil, errors.NewCode(codes.Unimplemented, "Fake{{$svcType}}HTTPClient.{{$name}} is not stubbed")
+}
+
+// {{$name}}Returns makes {{$name}} return stream and err.
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}Returns(stream {{$svcType}}_{{$name}}HTTPClient, err error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  f.{{$field}}Returns = &struct{ stream {{$svcType}}_{{$name}}HTTPClient; err error }{stream, err}
+}
+
+// {{$name}}ArgsForCall returns the context of the i-th call to {{$name}}.
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}ArgsForCall(i int) context.Context {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return f.{{$field}}Calls[i]
+}
+{{- else}}
+{{- if .ServerStreaming}}
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) ({{$svcType}}_{{$name}}HTTPClient, error) {
+{{- else}}
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) (*{{.Reply}}, error) {
+{{- end}}
+  f.mu.Lock()
+  f.{{$field}}Calls = append(f.{{$field}}Calls, struct{ ctx context.Context; in *{{.Request}} }{ctx, in})
+  stub, ret := f.{{$name}}Stub, f.{{$field}}Returns
+  f.mu.Unlock()
+
+  if stub != nil {
+    return stub(ctx, in, opts...)
+  }
+  if ret != nil {
+    {{- if .ServerStreaming}}
+    return ret.stream, ret.err
+    {{- else}}
+    return ret.reply, ret.err
+    {{- end}}
+  }
+  return nil, errors.NewCode(codes.Unimplemented, "Fake{{$svcType}}HTTPClient.{{$name}} is not stubbed")
+}
+{{- if .ServerStreaming}}
+
+// {{$name}}Returns makes {{$name}} return stream and err, such as a
+// Fake{{$svcType}}_{{$name}}HTTPClient replaying canned replies.
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}Returns(stream {{$svcType}}_{{$name}}HTTPClient, err error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  f.{{$field}}Returns = &struct{ stream {{$svcType}}_{{$name}}HTTPClient; err error }{stream, err}
+}
+{{- else}}
+
+// {{$name}}Returns makes {{$name}} return reply and err.
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}Returns(reply *{{.Reply}}, err error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  f.{{$field}}Returns = &struct{ reply *{{.Reply}}; err error }{reply, err}
+}
+{{- end}}
+
+// {{$name}}ArgsForCall returns the arguments of the i-th call to {{$name}}.
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}ArgsForCall(i int) (context.Context, *{{.Request}}) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return f.{{$field}}Calls[i].ctx, f.{{$field}}Calls[i].in
+}
+{{- end}}
+
+// {{$name}}CallCount returns the number of calls to {{$name}}.
+func (f *Fake{{$svcType}}HTTPClient) {{$name}}CallCount() int {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return len(f.{{$field}}Calls)
+}
+{{- if and .ServerStreaming (not .ClientStreaming)}}
+
+// Fake{{$svcType}}_{{$name}}HTTPClient is a {{$svcType}}_{{$name}}HTTPClient
+// replaying Replies, then reporting Err or io.EOF.
+type Fake{{$svcType}}_{{$name}}HTTPClient struct {
+  Replies []*{{.Reply}}
+  Err     error
+}
+
+func (x *Fake{{$svcType}}_{{$name}}HTTPClient) Recv() (*{{.Reply}}, error) {
+  if len(x.Replies) == 0 {
+    if x.Err != nil {
+      return nil, x.Err
+    }
+    return nil, io.EOF
+  }
+
+  reply := x.Replies[0]
+  x.Replies = x.Replies[1:]
+  return reply, nil
+}
+
+func (x *Fake{{$svcType}}_{{$name}}HTTPClient) Close() error {
+  return nil
+}
+{{- end}}
+
+{{end}}
+
+// Fake{{$svcType}}HTTPServer is an in-memory {{$svcType}}HTTPServer returning
+// canned responses, to be called directly or registered like any server.
+// Each method records its arguments, then calls its Stub func when set or
+// returns the values set with its Returns method. A method with neither fails
+// with codes.Unimplemented.
+type Fake{{$svcType}}HTTPServer struct {
+{{- range .MethodSets}}
+	{{- if .ClientStreaming}}
+	{{.Name}}Stub func(stream {{$svcType}}_{{.Name}}HTTPServer) error
+	{{- else if .ServerStreaming}}
+	{{.Name}}Stub func(in *{{.Request}}, stream {{$svcType}}_{{.Name}}HTTPServer) error
+	{{- else}}
+	{{.Name}}Stub func(ctx context.Context, in *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
+{{- end}}
+
+	mu sync.Mutex
+{{- range .MethodSets}}
+	{{- if .ClientStreaming}}
+	{{unexport .Name}}Calls []{{$svcType}}_{{.Name}}HTTPServer
+	{{- else if .ServerStreaming}}
+	{{unexport .Name}}Calls []struct{ in *{{.Request}}; stream {{$svcType}}_{{.Name}}HTTPServer }
+	{{unexport .Name}}Returns *struct{ replies []*{{.Reply}}; err error }
+	{{- else}}
+	{{unexport .Name}}Calls []struct{ ctx context.Context; in *{{.Request}} }
+	{{unexport .Name}}Returns *struct{ reply *{{.Reply}}; err error }
+	{{- end}}
+{{- end}}
+}
+
+var _ {{$svcType}}HTTPServer = (*Fake{{$svcType}}HTTPServer)(nil)
+
+{{range .MethodSets}}
+{{- $name := .Name}}
+{{- $field := unexport .Name}}
+{{- if .ClientStreaming}}
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}(stream {{$svcType}}_{{$name}}HTTPServer) error {
+  f.mu.Lock()
+  f.{{$field}}Calls = append(f.{{$field}}Calls, stream)
+  stub := f.{{$name}}Stub
+  f.mu.Unlock()
+
+  if stub != nil {
+    return stub(stream)
+  }
+  return errors.NewCode(codes.Unimplemented, "Fake{{$svcType}}HTTPServer.{{$name}} is not stubbed")
+}
+
+// {{$name}}ArgsForCall returns the stream of the i-th call to {{$name}}.
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}ArgsForCall(i int) {{$svcType}}_{{$name}}HTTPServer {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return f.{{$field}}Calls[i]
+}
+{{- else if .ServerStreaming}}
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}(in *{{.Request}}, stream {{$svcType}}_{{$name}}HTTPServer) error {
+  f.mu.Lock()
+  f.{{$field}}Calls = append(f.{{$field}}Calls, struct{ in *{{.Request}}; stream {{$svcType}}_{{$name}}HTTPServer }{in, stream})
+  stub, ret := f.{{$name}}Stub, f.{{$field}}Returns
+  f.mu.Unlock()
+
+  if stub != nil {
+    return stub(in, stream)
+  }
+  if ret != nil {
+    for _, reply := range ret.replies {
+      if err := stream.Send(reply); err != nil {
+        return err
+      }
+    }
+    return ret.err
+  }
+  return errors.NewCode(codes.Unimplemented, "Fake{{$svcType}}HTTPServer.{{$name}} is not stubbed")
+}
+
+// {{$name}}Returns makes {{$name}} send replies, then return err.
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}Returns(replies []*{{.Reply}}, err error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  f.{{$field}}Returns = &struct{ replies []*{{.Reply}}; err error }{replies, err}
+}
+
+// {{$name}}ArgsForCall returns the arguments of the i-th call to {{$name}}.
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}ArgsForCall(i int) (*{{.Request}}, {{$svcType}}_{{$name}}HTTPServer) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return f.{{$field}}Calls[i].in, f.{{$field}}Calls[i].stream
+}
+{{- else}}
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}(ctx context.Context, in *{{.Request}}) (*{{.Reply}}, error) {
+  f.mu.Lock()
+  f.{{$field}}Calls = append(f.{{$field}}Calls, struct{ ctx context.Context; in *{{.Request}} }{ctx, in})
+  stub, ret := f.{{$name}}Stub, f.{{$field}}Returns
+  f.mu.Unlock()
+
+  if stub != nil {
+    return stub(ctx, in)
+  }
+  if ret != nil {
+    return ret.reply, ret.err
+  }
+  return nil, errors.NewCode(codes.Unimplemented, "Fake{{$svcType}}HTTPServer.{{$name}} is not stubbed")
+}
+
+// {{$name}}Returns makes {{$name}} return reply and err.
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}Returns(reply *{{.Reply}}, err error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  f.{{$field}}Returns = &struct{ reply *{{.Reply}}; err error }{reply, err}
+}
+
+// {{$name}}ArgsForCall returns the arguments of the i-th call to {{$name}}.
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}ArgsForCall(i int) (context.Context, *{{.Request}}) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return f.{{$field}}Calls[i].ctx, f.{{$field}}Calls[i].in
+}
+{{- end}}
+
+// {{$name}}CallCount returns the number of calls to {{$name}}.
+func (f *Fake{{$svcType}}HTTPServer) {{$name}}CallCount() int {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+  return len(f.{{$field}}Calls)
+}
+{{end}}
@@ -2,12 +2,10 @@ package main
 
 import (
 	"fmt"
-	"net/http"
 	"strings"
 
-	"google.golang.org/genproto/googleapis/api/annotations"
+	"github.com/getfrontierhq/buf-public-apis/internal/httproute"
 	"google.golang.org/protobuf/compiler/protogen"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -16,24 +14,67 @@ const (
 	netHttpPackage = protogen.GoImportPath("net/http")
 	chiPackage     = protogen.GoImportPath("github.com/go-chi/chi/v5")
 	fmtPackage     = protogen.GoImportPath("fmt")
+	ioPackage      = protogen.GoImportPath("io")
+	syncPackage    = protogen.GoImportPath("sync")
+	codesPackage   = protogen.GoImportPath("google.golang.org/grpc/codes")
 	errorsPackage  = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors")
 	potPackage     = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp")
 	binderPackage  = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder")
 	optionPackage  = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option")
+	wsPackage      = protogen.GoImportPath("github.com/gorilla/websocket")
 
 	deprecationComment = "// Deprecated: Do not use."
 )
 
 var methodSets = make(map[string]int)
 
-// generateFile generates a _http.pb.go file.
-func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omitemptyPrefix string) *protogen.GeneratedFile {
-	if len(file.Services) == 0 || (omitempty && !hasHTTPRule(file.Services)) {
+// generateFile generates a _http.pb.go file, and a _http_fake.pb.go file when fake is set.
+func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omitemptyPrefix string, websocket, fake bool) *protogen.GeneratedFile {
+	if len(file.Services) == 0 || (omitempty && !httproute.HasHTTPRule(file.Services, websocket)) {
 		return nil
 	}
 
 	filename := file.GeneratedFilenamePrefix + "_http.pb.go"
 	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	generateHeader(gen, file, g)
+
+	services := generateFileContent(gen, file, g, omitempty, omitemptyPrefix, websocket)
+	if fake && len(services) != 0 {
+		generateFakeFile(gen, file, services)
+	}
+
+	return g
+}
+
+// generateFakeFile generates a _http_fake.pb.go file with the fakes of the
+// services rendered in the _http.pb.go file.
+func generateFakeFile(gen *protogen.Plugin, file *protogen.File, services []*serviceDescriptor) *protogen.GeneratedFile {
+	filename := file.GeneratedFilenamePrefix + "_http_fake.pb.go"
+	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	generateHeader(gen, file, g)
+
+	g.P("// This is a compile-time assertion to ensure that this generated file")
+	g.P("// is compatible with the pot package it is being compiled against.")
+	g.P("var _ = new(", contextPackage.Ident("Context"), ")")
+	g.P("var _ = new(", syncPackage.Ident("Mutex"), ")")
+	g.P("var _ = ", ioPackage.Ident("EOF"))
+	g.P("var _ = ", codesPackage.Ident("Unimplemented"))
+	g.P("var _ = ", errorsPackage.Ident("ErrGeneralBadRequest"))
+	g.P("var _ = new(", optionPackage.Ident("BinderOptions"), ")")
+
+	for _, serviceDesc := range services {
+		// the message types of other packages are imported by this file too.
+		for _, m := range serviceDesc.Methods {
+			g.QualifiedGoIdent(m.input)
+			g.QualifiedGoIdent(m.output)
+		}
+		g.P(serviceDesc.executeFake())
+	}
+
+	return g
+}
+
+func generateHeader(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile) {
 	g.P("// Code generated by protoc-gen-go-http. DO NOT EDIT.")
 	g.P("// versions:")
 	g.P(fmt.Sprintf("// - protoc-gen-go-http %s", release))
@@ -48,16 +89,12 @@ func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omi
 	g.P()
 	g.P("package ", file.GoPackageName)
 	g.P()
-
-	generateFileContent(gen, file, g, omitempty, omitemptyPrefix)
-
-	return g
 }
 
-// generateFileContent generates the file content.
-func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, omitempty bool, omitemptyPrefix string) {
+// generateFileContent generates the file content and returns the rendered services.
+func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, omitempty bool, omitemptyPrefix string, websocket bool) []*serviceDescriptor {
 	if len(file.Services) == 0 {
-		return
+		return nil
 	}
 
 	g.P("// This is a compile-time assertion to ensure that this generated file")
@@ -71,101 +108,60 @@ func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.
 	g.P("var _ = new(", binderPackage.Ident("RequestDecoder"), ")")
 	g.P("var _ = new(", optionPackage.Ident("BinderOptions"), ")")
 
+	var services []*serviceDescriptor
 	for _, service := range file.Services {
-		genService(gen, file, g, service, omitempty, omitemptyPrefix)
+		if serviceDesc := genService(gen, file, g, service, omitempty, omitemptyPrefix, websocket); serviceDesc != nil {
+			services = append(services, serviceDesc)
+		}
+	}
+
+	// only the clients of client and bidi streams dial websockets.
+	for _, serviceDesc := range services {
+		if serviceDesc.HasClientStreaming() {
+			g.P("var _ = new(", wsPackage.Ident("Dialer"), ")")
+			break
+		}
 	}
+
+	for _, serviceDesc := range services {
+		if serviceDesc.deprecated {
+			g.P("//")
+			g.P(deprecationComment)
+		}
+		g.P(serviceDesc.execute())
+	}
+
+	return services
 }
 
-func genService(_ *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service, omitempty bool, omitemptyPrefix string) {
+func genService(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service, omitempty bool, omitemptyPrefix string, websocket bool) *serviceDescriptor {
 	opts, ok := service.Desc.Options().(*descriptorpb.ServiceOptions)
-	if opts != nil && ok && opts.GetDeprecated() {
-		g.P("//")
-		g.P(deprecationComment)
-	}
 
 	// HTTP Server.
 	serviceDesc := &serviceDescriptor{
 		ServiceType: service.GoName,
 		ServiceName: string(service.Desc.FullName()),
 		Metadata:    file.Desc.Path(),
+		deprecated:  opts != nil && ok && opts.GetDeprecated(),
 	}
 
-	for _, method := range service.Methods {
-		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
-			continue
-		}
-
-		rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
-		if rule != nil && ok {
-			for _, bind := range rule.AdditionalBindings {
-				serviceDesc.Methods = append(serviceDesc.Methods, buildHTTPRule(g, service, method, bind, omitemptyPrefix))
-			}
-
-			serviceDesc.Methods = append(serviceDesc.Methods, buildHTTPRule(g, service, method, rule, omitemptyPrefix))
-			continue
-		}
-
-		if !omitempty {
-			path := fmt.Sprintf("%s/%s/%s", omitemptyPrefix, service.Desc.FullName(), method.Desc.Name())
-			serviceDesc.Methods = append(serviceDesc.Methods, buildMethodDesc(g, method, http.MethodPost, path))
-		}
-	}
-
-	if len(serviceDesc.Methods) != 0 {
-		g.P(serviceDesc.execute())
+	routes := httproute.ServiceRoutes(gen, service, httproute.Options{
+		Omitempty:       omitempty,
+		OmitemptyPrefix: omitemptyPrefix,
+		WebSocket:       websocket,
+	})
+	for _, route := range routes {
+		methodDesc := buildMethodDesc(g, route.Method, route.HTTPMethod, route.Path)
+		methodDesc.Body = route.Body
+		methodDesc.ResponseBody = route.ResponseBody
+		serviceDesc.Methods = append(serviceDesc.Methods, methodDesc)
 	}
-}
 
-func hasHTTPRule(services []*protogen.Service) bool {
-	for _, service := range services {
-		for _, method := range service.Methods {
-			if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
-				continue
-			}
-
-			rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
-			if rule != nil && ok {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func buildHTTPRule(g *protogen.GeneratedFile, service *protogen.Service, m *protogen.Method, rule *annotations.HttpRule, omitemptyPrefix string) *methodDescriptor {
-	var (
-		path   string
-		method string
-	)
-
-	switch pattern := rule.Pattern.(type) {
-	case *annotations.HttpRule_Get:
-		path = pattern.Get
-		method = http.MethodGet
-	case *annotations.HttpRule_Put:
-		path = pattern.Put
-		method = http.MethodPut
-	case *annotations.HttpRule_Post:
-		path = pattern.Post
-		method = http.MethodPost
-	case *annotations.HttpRule_Delete:
-		path = pattern.Delete
-		method = http.MethodDelete
-	case *annotations.HttpRule_Patch:
-		path = pattern.Patch
-		method = http.MethodPatch
-	case *annotations.HttpRule_Custom:
-		path = pattern.Custom.Path
-		method = pattern.Custom.Kind
-	default:
-		path = fmt.Sprintf("%s/%s/%s", omitemptyPrefix, service.Desc.FullName(), m.Desc.Name())
-		method = http.MethodPost
+	if len(serviceDesc.Methods) == 0 {
+		return nil
 	}
 
-	methodDesc := buildMethodDesc(g, m, method, path)
-
-	return methodDesc
+	return serviceDesc
 }
 
 func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method, method, path string) *methodDescriptor {
@@ -182,9 +178,14 @@ func buildMethodDesc(g *protogen.GeneratedFile, m *protogen.Method, method, path
 		Num:          methodSets[m.GoName],
 		Request:      g.QualifiedGoIdent(m.Input.GoIdent),
 		Reply:        g.QualifiedGoIdent(m.Output.GoIdent),
+		input:        m.Input.GoIdent,
+		output:       m.Output.GoIdent,
 		Comment:      comment,
 		Path:         path,
 		Method:       method,
+
+		ServerStreaming: m.Desc.IsStreamingServer(),
+		ClientStreaming: m.Desc.IsStreamingClient(),
 	}
 }
 
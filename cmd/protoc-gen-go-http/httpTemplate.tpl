@@ -17,19 +17,77 @@ type {{$svcType}}HTTPServer interface {
 	{{- if ne .Comment ""}}
 	{{.Comment}}
 	{{- end}}
+	{{- if .ClientStreaming}}
+	{{.Name}}(stream {{$svcType}}_{{.Name}}HTTPServer) error
+	{{- else if .ServerStreaming}}
+	{{.Name}}(in *{{.Request}}, stream {{$svcType}}_{{.Name}}HTTPServer) error
+	{{- else}}
 	{{.Name}}(ctx context.Context, in *{{.Request}}) (*{{.Reply}}, error)
+	{{- end}}
 {{- end}}
 }
 
-func Register{{$svcType}}HTTPServer(srv {{$svcType}}HTTPServer) http.Handler {
-  return gohttp.RegisterService(&_{{$svcType}}_HTTP_ServiceDesc, srv)
+func Register{{$svcType}}HTTPServer(srv {{$svcType}}HTTPServer, opts ...gohttp.ServerOption) http.Handler {
+  return gohttp.RegisterService(&_{{$svcType}}_HTTP_ServiceDesc, srv, opts...)
+}
+
+func Register{{$svcType}}HTTPServerWithChi(srv {{$svcType}}HTTPServer, router v5.Router, opts ...gohttp.ServerOption) http.Handler {
+  return gohttp.RegisterServiceWithChi(&_{{$svcType}}_HTTP_ServiceDesc, srv, router, opts...)
+}
+
+{{range .MethodSets}}
+{{- if or .ServerStreaming .ClientStreaming}}
+type {{$svcType}}_{{.Name}}HTTPServer interface {
+	{{- if and .ClientStreaming (not .ServerStreaming)}}
+	SendAndClose(*{{.Reply}}) error
+	{{- else}}
+	Send(*{{.Reply}}) error
+	{{- end}}
+	{{- if .ClientStreaming}}
+	Recv() (*{{.Request}}, error)
+	{{- end}}
+	gohttp.ServerStream
+}
+
+type {{unexport $svcType}}{{.Name}}HTTPServer struct {
+	gohttp.ServerStream
 }
 
-func Register{{$svcType}}HTTPServerWithChi(srv {{$svcType}}HTTPServer, router v5.Router) http.Handler {
-  return gohttp.RegisterServiceWithChi(&_{{$svcType}}_HTTP_ServiceDesc, srv, router)
+{{if and .ClientStreaming (not .ServerStreaming) -}}
+func (x *{{unexport $svcType}}{{.Name}}HTTPServer) SendAndClose(m *{{.Reply}}) error {
+	return x.ServerStream.SendMsg(m)
 }
+{{- else -}}
+func (x *{{unexport $svcType}}{{.Name}}HTTPServer) Send(m *{{.Reply}}) error {
+	return x.ServerStream.SendMsg(m)
+}
+{{- end}}
+{{if .ClientStreaming}}
+func (x *{{unexport $svcType}}{{.Name}}HTTPServer) Recv() (*{{.Request}}, error) {
+	m := new({{.Request}})
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{end}}
+{{- end}}
+{{- end}}
 
 {{range .Methods}}
+{{- if .ClientStreaming}}
+func _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler(srv interface{}, stream gohttp.ServerStream) error {
+  return srv.({{$svcType}}HTTPServer).{{.Name}}(&{{unexport $svcType}}{{.Name}}HTTPServer{stream})
+}
+{{- else if .ServerStreaming}}
+func _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler(srv interface{}, stream gohttp.ServerStream) error {
+  in := new({{.Request}})
+  if err := stream.RecvMsg(in); err != nil {
+    return err
+  }
+  return srv.({{$svcType}}HTTPServer).{{.Name}}(in, &{{unexport $svcType}}{{.Name}}HTTPServer{stream})
+}
+{{- else}}
 func _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler(ctx context.Context, srv interface{}, dec gohttp.DecoderFunc, middleware gohttp.MiddlewareFunc) (interface{}, error) {
   in := new({{.Request}})
   if err := dec(in); err != nil {
@@ -43,6 +101,7 @@ func _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler(ctx context.Context, srv inter
   })
   return h(ctx, in)
 }
+{{- end}}
 {{end}}
 
 var _{{$svcType}}_HTTP_ServiceDesc = gohttp.ServiceDescriptor{
@@ -50,66 +109,191 @@ var _{{$svcType}}_HTTP_ServiceDesc = gohttp.ServiceDescriptor{
   HandlerType: (*{{$svcType}}HTTPServer)(nil),
   Methods: []gohttp.MethodDescriptor{
     {{- range .Methods}}
+    {{- if not (or .ServerStreaming .ClientStreaming)}}
     {
       MethodName: "{{.Name}}",
+      Operation: Operation_{{$svcType}}_{{.OriginalName}},
       HttpMethod: "{{.Method}}",
       HttpPath: "{{.Path}}",
+      Body: "{{.Body}}",
+      ResponseBody: "{{.ResponseBody}}",
       Handler: _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler,
     },
     {{- end}}
+    {{- end}}
+  },
+  Streams: []gohttp.StreamDescriptor{
+    {{- range .Methods}}
+    {{- if or .ServerStreaming .ClientStreaming}}
+    {
+      StreamName: "{{.Name}}",
+      HttpMethod: "{{.Method}}",
+      HttpPath: "{{.Path}}",
+      Body: "{{.Body}}",
+      ResponseBody: "{{.ResponseBody}}",
+      Handler: _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler,
+      ServerStreams: {{.ServerStreaming}},
+      ClientStreams: {{.ClientStreaming}},
+    },
+    {{- end}}
+    {{- end}}
   },
 }
 
 type {{$svcType}}HTTPClient interface {
 {{- range .MethodSets}}
+	{{- if .ClientStreaming}}
+	{{.Name}}(ctx context.Context, opts ...option.BinderOption) ({{$svcType}}_{{.Name}}HTTPClient, error)
+	{{- else if .ServerStreaming}}
+	{{.Name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) ({{$svcType}}_{{.Name}}HTTPClient, error)
+	{{- else}}
 	{{.Name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) (*{{.Reply}}, error)
+	{{- end}}
 {{- end}}
 }
 
 type {{$svcType}}HTTPClientImpl struct{
   baseUrl string
 	client  *http.Client
+	{{- if .HasClientStreaming}}
+	dialer *websocket.Dialer
+	{{- end}}
+	callOptions []option.BinderOption
 }
 
 func New{{$svcType}}HTTPClient (opts ...option.ClientOption) {{$svcType}}HTTPClient {
   options := option.NewClientOptions(opts...)
 	return &{{$svcType}}HTTPClientImpl{
     baseUrl: options.BaseURL,
-    client: &http.Client{
-      Timeout: options.Timeout,
-    },
+    client: options.NewHTTPClient(),
+    {{- if .HasClientStreaming}}
+    dialer: options.NewWebSocketDialer(),
+    {{- end}}
+    callOptions: options.CallOptions,
   }
 }
 
 {{range .MethodSets}}
+{{- if .ClientStreaming}}
+type {{$svcType}}_{{.Name}}HTTPClient interface {
+	Send(*{{.Request}}) error
+	{{- if .ServerStreaming}}
+	Recv() (*{{.Reply}}, error)
+	CloseSend() error
+	Close() error
+	{{- else}}
+	CloseAndRecv() (*{{.Reply}}, error)
+	{{- end}}
+}
+
+type {{unexport $svcType}}{{.Name}}HTTPClient struct {
+	*binder.WebSocketClientConn
+}
+
+func (x *{{unexport $svcType}}{{.Name}}HTTPClient) Send(m *{{.Request}}) error {
+	return x.WebSocketClientConn.SendMsg(m)
+}
+{{if .ServerStreaming}}
+func (x *{{unexport $svcType}}{{.Name}}HTTPClient) Recv() (*{{.Reply}}, error) {
+	m := new({{.Reply}})
+	if err := x.WebSocketClientConn.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- else}}
+func (x *{{unexport $svcType}}{{.Name}}HTTPClient) CloseAndRecv() (*{{.Reply}}, error) {
+	defer x.WebSocketClientConn.Close()
+	if err := x.WebSocketClientConn.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new({{.Reply}})
+	if err := x.WebSocketClientConn.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- end}}
+
+func (c *{{$svcType}}HTTPClientImpl) {{.Name}}(ctx context.Context, opts ...option.BinderOption) ({{$svcType}}_{{.Name}}HTTPClient, error) {
+  opts = append(c.callOptions[:len(c.callOptions):len(c.callOptions)], opts...)
+  opts = append(opts, option.WithOperation(Operation_{{$svcType}}_{{.OriginalName}}), option.WithPathTemplate({{$svcType}}_{{.OriginalName}}_Path), option.WithResponseBody("{{.ResponseBody}}"), option.WithDialer(c.dialer))
+  conn, err := binder.DialWebSocket(ctx, c.baseUrl, opts...)
+  if err != nil {
+    return nil, err
+  }
+  return &{{unexport $svcType}}{{.Name}}HTTPClient{conn}, nil
+}
+{{- else if .ServerStreaming}}
+type {{$svcType}}_{{.Name}}HTTPClient interface {
+	Recv() (*{{.Reply}}, error)
+	Close() error
+}
+
+type {{unexport $svcType}}{{.Name}}HTTPClient struct {
+	*binder.StreamDecoder
+}
+
+func (x *{{unexport $svcType}}{{.Name}}HTTPClient) Recv() (*{{.Reply}}, error) {
+	m := new({{.Reply}})
+	if err := x.StreamDecoder.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *{{$svcType}}HTTPClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) ({{$svcType}}_{{.Name}}HTTPClient, error) {
+  req, err := http.NewRequest({{$svcType}}_{{.OriginalName}}_Method, c.baseUrl, nil)
+  if err != nil {
+    return nil, err
+  }
+  opts = append(c.callOptions[:len(c.callOptions):len(c.callOptions)], opts...)
+  opts = append(opts, option.WithOperation(Operation_{{$svcType}}_{{.OriginalName}}), option.WithPathTemplate({{$svcType}}_{{.OriginalName}}_Path), option.WithBody("{{.Body}}"), option.WithResponseBody("{{.ResponseBody}}"), option.WithHeader(option.AcceptHeader, option.ContentTypeEventStream))
+  enc := binder.NewRequestEncoder(req, opts...)
+  if err = enc.Bind(in); err != nil {
+      return nil, err
+  }
+  req = req.WithContext(option.NewBinderContext(ctx, enc.Opts))
+  // the stream outlives the client timeout, it is bounded by ctx instead.
+  client := *c.client
+  client.Timeout = 0
+  res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := errors.ErrorMap[res.StatusCode]; err != nil {
+		defer res.Body.Close()
+		return nil, binder.NewResponseDecoder(res, opts...).BindError()
+	}
+	return &{{unexport $svcType}}{{.Name}}HTTPClient{binder.NewStreamDecoder(res, opts...)}, nil
+}
+{{- else}}
 func (c *{{$svcType}}HTTPClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) (*{{.Reply}}, error) {
 	out := new({{.Reply}})
-  url := fmt.Sprintf("%s%s", c.baseUrl, {{$svcType}}_{{.OriginalName}}_Path)
-  req, err := http.NewRequest({{$svcType}}_{{.OriginalName}}_Method, url, nil)
+  req, err := http.NewRequest({{$svcType}}_{{.OriginalName}}_Method, c.baseUrl, nil)
   if err != nil {
     return nil, err
   }
-  opts = append(opts, option.WithOperation(Operation_{{$svcType}}_{{.OriginalName}}))
-  if err = binder.NewRequestEncoder(req, opts...).Bind(in); err != nil {
+  opts = append(c.callOptions[:len(c.callOptions):len(c.callOptions)], opts...)
+  opts = append(opts, option.WithOperation(Operation_{{$svcType}}_{{.OriginalName}}), option.WithPathTemplate({{$svcType}}_{{.OriginalName}}_Path), option.WithBody("{{.Body}}"), option.WithResponseBody("{{.ResponseBody}}"))
+  enc := binder.NewRequestEncoder(req, opts...)
+  if err = enc.Bind(in); err != nil {
       return nil, err
   }
-  req = req.WithContext(ctx)
+  req = req.WithContext(option.NewBinderContext(ctx, enc.Opts))
   res, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
   defer res.Body.Close()
-	dec := &binder.ResponseDecoder{Response: res}
+	dec := binder.NewResponseDecoder(res, opts...)
 	if err := errors.ErrorMap[res.StatusCode]; err != nil {
-		customErr := new(errors.Error)
-    if err := dec.BindBody(customErr); err != nil {
-      return nil, err
-    }
-		return nil, customErr
+		return nil, dec.BindError()
 	}
 	if err := dec.BindBody(out); err != nil {
     return nil, err
   }
 	return out, nil
 }
+{{- end}}
 {{end}}
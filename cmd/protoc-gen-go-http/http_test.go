@@ -0,0 +1,200 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/internal/plugintest"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const libraryProto = `
+name: "example/v1/library.proto"
+package: "example.v1"
+dependency: "google/api/annotations.proto"
+options { go_package: "example.com/example/v1;examplev1" }
+message_type {
+  name: "Book"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+}
+message_type {
+  name: "GetBookRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+}
+service {
+  name: "Library"
+  method {
+    name: "GetBook"
+    input_type: ".example.v1.GetBookRequest"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { get: "/v1/{name=shelves/*/books/*}" } }
+  }
+  method {
+    name: "WatchBooks"
+    input_type: ".example.v1.GetBookRequest"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { get: "/v1/{name=shelves/*}/books:watch" } }
+    server_streaming: true
+  }
+}
+syntax: "proto3"
+`
+
+// generate runs generateFile on the file described by content and returns the
+// files it generates.
+func generate(t *testing.T, content string, websocket, fake bool) map[string]string {
+	return plugintest.Run(t, content, func(gen *protogen.Plugin) error {
+		for _, f := range gen.Files {
+			if f.Generate {
+				generateFile(gen, f, true, "", websocket, fake)
+			}
+		}
+		return nil
+	})
+}
+
+// fileNames returns the sorted names of files.
+func fileNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// declarations returns the names of the types and functions declared by a Go
+// file, methods being named Type.Method.
+func declarations(t *testing.T, name, content string) map[string]bool {
+	f, err := parser.ParseFile(token.NewFileSet(), name, content, 0)
+	if err != nil {
+		t.Fatalf("%s: %v\n%s", name, err, content)
+	}
+
+	decls := make(map[string]bool)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				decls[d.Name.Name] = true
+				continue
+			}
+			recv := d.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			decls[recv.(*ast.Ident).Name+"."+d.Name.Name] = true
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					decls[ts.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+func TestGenerateFileFake(t *testing.T) {
+	files := generate(t, libraryProto, false, true)
+	names := fileNames(files)
+	want := []string{"example.com/example/v1/library_http.pb.go", "example.com/example/v1/library_http_fake.pb.go"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Fatalf("got files %v, want %v", names, want)
+	}
+
+	decls := declarations(t, want[1], files[want[1]])
+	for _, decl := range []string{
+		"FakeLibraryHTTPClient",
+		"FakeLibraryHTTPClient.GetBook",
+		"FakeLibraryHTTPClient.GetBookReturns",
+		"FakeLibraryHTTPClient.GetBookArgsForCall",
+		"FakeLibraryHTTPClient.GetBookCallCount",
+		"FakeLibraryHTTPClient.WatchBooks",
+		"FakeLibraryHTTPClient.WatchBooksReturns",
+		"FakeLibrary_WatchBooksHTTPClient",
+		"FakeLibrary_WatchBooksHTTPClient.Recv",
+		"FakeLibraryHTTPServer",
+		"FakeLibraryHTTPServer.GetBook",
+		"FakeLibraryHTTPServer.GetBookReturns",
+		"FakeLibraryHTTPServer.WatchBooks",
+		"FakeLibraryHTTPServer.WatchBooksReturns",
+		"FakeLibraryHTTPServer.WatchBooksArgsForCall",
+	} {
+		if !decls[decl] {
+			t.Errorf("%s is not declared by the fake", decl)
+		}
+	}
+
+	// the service file declares the interfaces the fakes implement.
+	decls = declarations(t, want[0], files[want[0]])
+	for _, decl := range []string{"LibraryHTTPClient", "LibraryHTTPServer", "Library_WatchBooksHTTPClient"} {
+		if !decls[decl] {
+			t.Errorf("%s is not declared by the service file", decl)
+		}
+	}
+}
+
+func TestGenerateFileWithoutFake(t *testing.T) {
+	names := fileNames(generate(t, libraryProto, false, false))
+	if len(names) != 1 || names[0] != "example.com/example/v1/library_http.pb.go" {
+		t.Fatalf("got files %v, want the service file alone", names)
+	}
+}
+
+var dialerField = regexp.MustCompile(`dialer\s+\*websocket\.Dialer`)
+
+func TestGenerateFileWebSocketDialer(t *testing.T) {
+	chatProto := strings.Replace(libraryProto, "service {", `service {
+  name: "Chat"
+  method {
+    name: "Talk"
+    input_type: ".example.v1.Book"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { get: "/v1/chat" } }
+    client_streaming: true
+    server_streaming: true
+  }
+}
+service {`, 1)
+
+	tests := []struct {
+		name       string
+		content    string
+		websocket  bool
+		wantDialer bool
+	}{
+		{name: "without websocket", content: chatProto},
+		{name: "without client streams", content: libraryProto, websocket: true},
+		{name: "client streams", content: chatProto, websocket: true, wantDialer: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := "example.com/example/v1/library_http.pb.go"
+			content := generate(t, tt.content, tt.websocket, false)[name]
+
+			f, err := parser.ParseFile(token.NewFileSet(), name, content, parser.ImportsOnly)
+			if err != nil {
+				t.Fatalf("%s: %v\n%s", name, err, content)
+			}
+			imported := false
+			for _, imp := range f.Imports {
+				imported = imported || imp.Path.Value == `"github.com/gorilla/websocket"`
+			}
+
+			if imported != tt.wantDialer {
+				t.Fatalf("got websocket imported %v, want %v", imported, tt.wantDialer)
+			}
+			if got := dialerField.MatchString(content); got != tt.wantDialer {
+				t.Fatalf("got dialer field %v, want %v", got, tt.wantDialer)
+			}
+		})
+	}
+}
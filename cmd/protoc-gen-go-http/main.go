@@ -12,6 +12,8 @@ var (
 	showVersion     = flag.Bool("version", false, "print the version and exit")
 	omitempty       = flag.Bool("omitempty", true, "omit if google.api is empty")
 	omitemptyPrefix = flag.String("omitempty_prefix", "", "omit if google.api is empty")
+	websocket       = flag.Bool("websocket", false, "generate client and bidi streaming methods over websocket")
+	fake            = flag.Bool("fake", false, "generate in-memory fakes of the client and server interfaces")
 )
 
 func main() {
@@ -29,7 +31,7 @@ func main() {
 			if !f.Generate {
 				continue
 			}
-			generateFile(gen, f, *omitempty, *omitemptyPrefix)
+			generateFile(gen, f, *omitempty, *omitemptyPrefix, *websocket, *fake)
 		}
 		return nil
 	})
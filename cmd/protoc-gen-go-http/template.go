@@ -5,17 +5,26 @@ import (
 	_ "embed"
 	"strings"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/compiler/protogen"
 )
 
 //go:embed httpTemplate.tpl
 var httpTemplate string
 
+//go:embed fakeTemplate.tpl
+var fakeTemplate string
+
 type serviceDescriptor struct {
 	ServiceType string // Greeter
 	ServiceName string // helloworld.Greeter
 	Metadata    string // api/helloworld/helloworld.proto
 	Methods     []*methodDescriptor
 	MethodSets  map[string]*methodDescriptor
+
+	deprecated bool
 }
 
 type methodDescriptor struct {
@@ -32,16 +41,44 @@ type methodDescriptor struct {
 	Method       string
 	Body         string
 	ResponseBody string
+
+	// streaming
+	ServerStreaming bool
+	ClientStreaming bool
+
+	// the message types, for the imports of the files using them.
+	input, output protogen.GoIdent
 }
 
 func (s *serviceDescriptor) execute() string {
+	return s.executeTemplate("http", httpTemplate)
+}
+
+// HasClientStreaming reports whether the service has client or bidi streams,
+// whose clients dial websockets.
+func (s *serviceDescriptor) HasClientStreaming() bool {
+	for _, m := range s.Methods {
+		if m.ClientStreaming {
+			return true
+		}
+	}
+
+	return false
+}
+
+// executeFake renders the fakes of the service interfaces.
+func (s *serviceDescriptor) executeFake() string {
+	return s.executeTemplate("fake", fakeTemplate)
+}
+
+func (s *serviceDescriptor) executeTemplate(name, text string) string {
 	s.MethodSets = make(map[string]*methodDescriptor)
 	for _, m := range s.Methods {
 		s.MethodSets[m.Name] = m
 	}
 
 	buf := new(bytes.Buffer)
-	tmpl, err := template.New("http").Parse(strings.TrimSpace(httpTemplate))
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"unexport": unexport}).Parse(strings.TrimSpace(text))
 	if err != nil {
 		panic(err)
 	}
@@ -52,3 +89,9 @@ func (s *serviceDescriptor) execute() string {
 
 	return strings.Trim(buf.String(), "\r\n")
 }
+
+// unexport lowercases the first letter of an identifier.
+func unexport(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
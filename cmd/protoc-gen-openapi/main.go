@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/getfrontierhq/buf-public-apis/internal/httproute"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var (
+	showVersion     = flag.Bool("version", false, "print the version and exit")
+	omitempty       = flag.Bool("omitempty", true, "omit if google.api is empty")
+	omitemptyPrefix = flag.String("omitempty_prefix", "", "omit if google.api is empty")
+	websocket       = flag.Bool("websocket", false, "document client and bidi streaming methods served over websocket")
+	format          = flag.String("format", "yaml", "output format, yaml or json")
+	apiVersion      = flag.String("api_version", "1.0.0", "version of the documented API")
+	jsonNames       = flag.String("json_names", "json", "property names, json for the protojson names or proto for the field names")
+	statusErrors    = flag.Bool("status_errors", false, "document errors as google.rpc.Status, the body of gohttp.StatusErrorEncoder")
+)
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-openapi %v\n", release)
+		return
+	}
+
+	protogen.Options{
+		ParamFunc: flag.CommandLine.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		if *format != "yaml" && *format != "json" {
+			return fmt.Errorf("invalid format %q, expected yaml or json", *format)
+		}
+		if *jsonNames != "json" && *jsonNames != "proto" {
+			return fmt.Errorf("invalid json_names %q, expected json or proto", *jsonNames)
+		}
+
+		opts := options{
+			Routes: httproute.Options{
+				Omitempty:       *omitempty,
+				OmitemptyPrefix: *omitemptyPrefix,
+				WebSocket:       *websocket,
+			},
+			Format:       *format,
+			APIVersion:   *apiVersion,
+			StatusErrors: *statusErrors,
+			ProtoNames:   *jsonNames == "proto",
+		}
+
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			generateFile(gen, f, opts)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,690 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/getfrontierhq/buf-public-apis/internal/httproute"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	openAPIVersion  = "3.1.0"
+	schemaRefPrefix = "#/components/schemas/"
+
+	contentTypeJSON        = "application/json"
+	contentTypeEventStream = "text/event-stream"
+	contentTypeNDJSON      = "application/x-ndjson"
+	contentTypeAny         = "*/*"
+
+	errorSchemaName  = "gohttp.Error"
+	statusSchemaName = "google.rpc.Status"
+	anySchemaName    = "google.protobuf.Any"
+
+	httpBodyName protoreflect.FullName = "google.api.HttpBody"
+)
+
+type options struct {
+	Routes       httproute.Options
+	Format       string // yaml or json
+	APIVersion   string
+	StatusErrors bool
+	ProtoNames   bool // name properties after the proto fields
+}
+
+// generator builds the OpenAPI document of a service, collecting the schemas
+// of the messages and enums reached from its routes.
+type generator struct {
+	gen  *protogen.Plugin
+	opts options
+	doc  *document
+
+	bindings     map[*protogen.Method]int
+	operationIDs map[*protogen.Method]int
+	referenced   map[protoreflect.FullName]bool
+	pending      []interface{} // *protogen.Message or *protogen.Enum
+}
+
+// generateFile generates a .openapi.yaml or .openapi.json file per service of
+// file with HTTP routes.
+func generateFile(gen *protogen.Plugin, file *protogen.File, opts options) {
+	for _, service := range file.Services {
+		routes := httproute.ServiceRoutes(gen, service, opts.Routes)
+		if len(routes) == 0 {
+			continue
+		}
+
+		g := &generator{
+			gen:          gen,
+			opts:         opts,
+			bindings:     make(map[*protogen.Method]int),
+			operationIDs: make(map[*protogen.Method]int),
+			referenced:   make(map[protoreflect.FullName]bool),
+		}
+
+		content, err := g.generate(service, routes)
+		if err != nil {
+			gen.Error(fmt.Errorf("%s: %w", service.Desc.FullName(), err))
+			continue
+		}
+
+		filename := fmt.Sprintf("%s.%s.openapi.%s", strings.TrimSuffix(file.Desc.Path(), ".proto"), service.Desc.Name(), opts.Format)
+		gen.NewGeneratedFile(filename, "").Write(content)
+	}
+}
+
+func (g *generator) generate(service *protogen.Service, routes []*httproute.Route) ([]byte, error) {
+	g.doc = &document{
+		OpenAPI: openAPIVersion,
+		Info: info{
+			Title:       string(service.Desc.FullName()),
+			Description: description(service.Comments.Leading),
+			Version:     g.opts.APIVersion,
+		},
+		Tags: []tag{{
+			Name:        string(service.Desc.Name()),
+			Description: description(service.Comments.Leading),
+		}},
+		Paths: newOrderedMap[*orderedMap[*operation]](),
+		Components: components{
+			Schemas: newOrderedMap[*schema](),
+		},
+	}
+
+	for _, route := range routes {
+		g.bindings[route.Method]++
+	}
+	for _, route := range routes {
+		g.addRoute(service, route)
+	}
+
+	// the schemas of the messages may reference further messages, which are
+	// appended to pending while it is walked.
+	for i := 0; i < len(g.pending); i++ {
+		switch x := g.pending[i].(type) {
+		case *protogen.Message:
+			g.doc.Components.Schemas.set(string(x.Desc.FullName()), g.messageSchema(x))
+		case *protogen.Enum:
+			g.doc.Components.Schemas.set(string(x.Desc.FullName()), enumSchema(x))
+		}
+	}
+	g.addErrorSchemas()
+
+	if g.opts.Format == "json" {
+		return json.MarshalIndent(g.doc, "", "  ")
+	}
+
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(g.doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *generator) addRoute(service *protogen.Service, route *httproute.Route) {
+	m := route.Method
+	op := &operation{
+		OperationID: g.operationID(service, m),
+		Description: description(m.Comments.Leading),
+		Tags:        []string{string(service.Desc.Name())},
+		Deprecated:  m.Desc.Options().(*descriptorpb.MethodOptions).GetDeprecated(),
+		Responses:   newOrderedMap[*response](),
+	}
+
+	path, params := g.pathParameters(m.Input, route.Template)
+	op.Parameters = params
+
+	httpMethod := route.HTTPMethod
+	switch {
+	case m.Desc.IsStreamingClient():
+		// the WebSocket handshake is always a GET request.
+		httpMethod = http.MethodGet
+		op.Responses.set("101", &response{
+			Description: fmt.Sprintf("Switching Protocols. The %s messages are sent as JSON text frames, followed by an empty frame once done sending. "+
+				"The %s messages are received as {\"result\": ...} frames and an error as an {\"error\": ...} frame.", m.Input.Desc.Name(), m.Output.Desc.Name()),
+		})
+	case m.Desc.IsStreamingServer() && isHttpBody(responseMessage(route)):
+		g.addRequest(op, route)
+		op.Responses.set("200", &response{
+			Description: "The raw chunks of a single body, typed by the content type of the first one.",
+			Content:     content(contentTypeAny, binarySchema()),
+		})
+	case m.Desc.IsStreamingServer():
+		g.addRequest(op, route)
+		op.Responses.set("200", &response{
+			Description: fmt.Sprintf("A stream of %s, one data event per message. With Accept: %s, one {\"result\": ...} line per message instead. "+
+				"An error after the first message is sent as an error event or an {\"error\": ...} line.", m.Output.Desc.Name(), contentTypeNDJSON),
+			Content: content(
+				contentTypeEventStream, g.messageRef(m.Output),
+				contentTypeNDJSON, &schema{
+					Type: "object",
+					Properties: properties(
+						"result", g.messageRef(m.Output),
+						"error", g.errorRef(),
+					),
+				},
+			),
+		})
+	default:
+		g.addRequest(op, route)
+
+		body := content(contentTypeJSON, g.messageRef(m.Output))
+		switch {
+		case isHttpBody(responseMessage(route)):
+			body = content(contentTypeAny, binarySchema())
+		case route.ResponseBody != "":
+			body = content(contentTypeJSON, g.fieldSchema(httproute.FindField(m.Output, route.ResponseBody)))
+		}
+		op.Responses.set("200", &response{
+			Description: "OK",
+			Content:     body,
+		})
+	}
+
+	op.Responses.set("default", &response{
+		Description: "An error.",
+		Content:     content(contentTypeJSON, g.errorRef()),
+	})
+
+	item, ok := g.doc.Paths.get(path)
+	if !ok {
+		item = newOrderedMap[*operation]()
+		g.doc.Paths.set(path, item)
+	}
+
+	key := operationKey(httpMethod)
+	if _, ok := item.get(key); ok {
+		g.gen.Error(fmt.Errorf("%s: route %s %s is already bound", m.Desc.FullName(), httpMethod, route.Path))
+		return
+	}
+	item.set(key, op)
+}
+
+// addRequest documents the query parameters and the body of a route, following
+// the binding of the gohttp server.
+func (g *generator) addRequest(op *operation, route *httproute.Route) {
+	in := route.Method.Input
+	switch route.Body {
+	case httproute.BodyWildcard:
+		body := content(contentTypeJSON, g.messageRef(in))
+		if isHttpBody(in) {
+			body = content(contentTypeAny, binarySchema())
+		}
+		op.RequestBody = &requestBody{
+			Required: true,
+			Content:  body,
+		}
+		// the body maps the whole request, the query string is ignored.
+		return
+	case "":
+	default:
+		field := httproute.FindField(in, route.Body)
+		body := content(contentTypeJSON, g.fieldSchema(field))
+		if !field.Desc.IsList() && isHttpBody(field.Message) {
+			body = content(contentTypeAny, binarySchema())
+		}
+		op.RequestBody = &requestBody{
+			Description: description(field.Comments.Leading),
+			Required:    true,
+			Content:     body,
+		}
+	}
+
+	for _, qf := range route.QueryFields() {
+		field := qf.Field()
+		param := &parameter{
+			Name:        qf.Name,
+			In:          "query",
+			Description: description(field.Comments.Leading),
+			Deprecated:  field.Desc.Options().(*descriptorpb.FieldOptions).GetDeprecated(),
+			Schema:      g.fieldSchema(field),
+		}
+		param.Schema.Description = ""
+		param.Schema.Deprecated = false
+
+		if field.Desc.IsMap() {
+			// map entries are keyed as name[key].
+			param.Style = "deepObject"
+			param.Explode = true
+		}
+
+		op.Parameters = append(op.Parameters, param)
+	}
+}
+
+// pathParameters returns the OpenAPI path of tmpl and its parameters. The
+// literal segments of a variable spanning several segments are written out
+// and its wildcards documented as parameters named after the field and the
+// preceding literal, such as name.books, so that additional bindings of other
+// collections get paths of their own.
+func (g *generator) pathParameters(in *protogen.Message, tmpl *httprule.Template) (string, []*parameter) {
+	var (
+		b      strings.Builder
+		params []*parameter
+	)
+
+	for i := 0; i < len(tmpl.Segments); i++ {
+		if v, ok := variableAt(tmpl, i); ok {
+			field := resolveField(in, v.FieldPath)
+			if segments := tmpl.Segments[v.Start:v.End]; len(segments) == 1 {
+				s := g.kindSchema(field)
+				if segments[0].Kind != httprule.SegmentWildcard {
+					s.Pattern = segmentsPattern(segments)
+				}
+
+				b.WriteString("/{" + v.FieldPath + "}")
+				params = append(params, &parameter{
+					Name:        v.FieldPath,
+					In:          "path",
+					Description: description(field.Comments.Leading),
+					Required:    true,
+					Schema:      s,
+				})
+				i = v.End - 1
+				continue
+			}
+
+			for j := v.Start; j < v.End; j++ {
+				seg := tmpl.Segments[j]
+				if seg.Kind == httprule.SegmentLiteral {
+					b.WriteString("/" + seg.Literal)
+					continue
+				}
+
+				name := fmt.Sprintf("%s.%d", v.FieldPath, j-v.Start)
+				desc := fmt.Sprintf("Segment %d of %s.", j-v.Start, v.FieldPath)
+				if j > v.Start && tmpl.Segments[j-1].Kind == httprule.SegmentLiteral {
+					name = v.FieldPath + "." + tmpl.Segments[j-1].Literal
+					desc = fmt.Sprintf("The %s segment of %s.", tmpl.Segments[j-1].Literal, v.FieldPath)
+				}
+
+				b.WriteString("/{" + name + "}")
+				params = append(params, &parameter{
+					Name:        name,
+					In:          "path",
+					Description: desc,
+					Required:    true,
+					Schema:      &schema{Type: "string", Pattern: segmentsPattern([]httprule.Segment{seg})},
+				})
+			}
+			i = v.End - 1
+			continue
+		}
+
+		b.WriteString("/")
+		seg := tmpl.Segments[i]
+		if seg.Kind == httprule.SegmentLiteral {
+			b.WriteString(seg.Literal)
+			continue
+		}
+
+		// an unnamed wildcard matches without binding a field.
+		name := fmt.Sprintf("wildcard%d", i)
+		b.WriteString("{" + name + "}")
+		params = append(params, &parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &schema{Type: "string", Pattern: segmentsPattern([]httprule.Segment{seg})},
+		})
+	}
+
+	if tmpl.Verb != "" {
+		b.WriteString(":" + tmpl.Verb)
+	}
+
+	return b.String(), params
+}
+
+// operationID returns Service_Method for the main rule of a method, which is
+// routed after its additional bindings, and Service_Method_N for the N-th
+// additional binding.
+func (g *generator) operationID(service *protogen.Service, m *protogen.Method) string {
+	id := fmt.Sprintf("%s_%s", service.Desc.Name(), m.Desc.Name())
+	n := g.operationIDs[m]
+	g.operationIDs[m]++
+	if n == g.bindings[m]-1 {
+		return id
+	}
+
+	return fmt.Sprintf("%s_%d", id, n+1)
+}
+
+func (g *generator) messageSchema(msg *protogen.Message) *schema {
+	s := &schema{
+		Type:        "object",
+		Description: description(msg.Comments.Leading),
+		Deprecated:  msg.Desc.Options().(*descriptorpb.MessageOptions).GetDeprecated(),
+		Properties:  newOrderedMap[*schema](),
+	}
+
+	for _, field := range msg.Fields {
+		fs := g.fieldSchema(field)
+
+		behaviors, _ := proto.GetExtension(field.Desc.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+		for _, behavior := range behaviors {
+			switch behavior {
+			case annotations.FieldBehavior_REQUIRED:
+				s.Required = append(s.Required, g.propertyName(field))
+			case annotations.FieldBehavior_OUTPUT_ONLY:
+				fs.ReadOnly = true
+			case annotations.FieldBehavior_INPUT_ONLY:
+				fs.WriteOnly = true
+			}
+		}
+
+		s.Properties.set(g.propertyName(field), fs)
+	}
+
+	return s
+}
+
+// propertyName is the name of field in the JSON bodies, as protojson names it
+// with or without UseProtoNames.
+func (g *generator) propertyName(field *protogen.Field) string {
+	if g.opts.ProtoNames {
+		return field.Desc.TextName()
+	}
+
+	return field.Desc.JSONName()
+}
+
+func enumSchema(enum *protogen.Enum) *schema {
+	s := &schema{
+		Type:        "string",
+		Description: description(enum.Comments.Leading),
+		Deprecated:  enum.Desc.Options().(*descriptorpb.EnumOptions).GetDeprecated(),
+	}
+
+	for _, v := range enum.Values {
+		s.Enum = append(s.Enum, string(v.Desc.Name()))
+	}
+
+	return s
+}
+
+// fieldSchema returns the schema of the protojson form of field.
+func (g *generator) fieldSchema(field *protogen.Field) *schema {
+	var s *schema
+	switch {
+	case field.Desc.IsMap():
+		s = &schema{
+			Type:                 "object",
+			AdditionalProperties: g.kindSchema(field.Message.Fields[1]),
+		}
+	case field.Desc.IsList():
+		s = &schema{
+			Type:  "array",
+			Items: g.kindSchema(field),
+		}
+	default:
+		s = g.kindSchema(field)
+	}
+
+	s.Description = description(field.Comments.Leading)
+	s.Deprecated = field.Desc.Options().(*descriptorpb.FieldOptions).GetDeprecated()
+
+	return s
+}
+
+// kindSchema returns the schema of a single value of field.
+func (g *generator) kindSchema(field *protogen.Field) *schema {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return &schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &schema{Type: "integer", Format: "uint32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// protojson encodes 64-bit integers as strings.
+		return &schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return &schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		return &schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		if field.Enum.Desc.FullName() == "google.protobuf.NullValue" {
+			return &schema{Type: "null"}
+		}
+		return g.ref(field.Enum.Desc.FullName(), field.Enum)
+	default:
+		if s, ok := wellKnownSchema(field.Message.Desc.FullName()); ok {
+			return s
+		}
+		return g.messageRef(field.Message)
+	}
+}
+
+func (g *generator) messageRef(msg *protogen.Message) *schema {
+	if s, ok := wellKnownSchema(msg.Desc.FullName()); ok {
+		return s
+	}
+
+	return g.ref(msg.Desc.FullName(), msg)
+}
+
+// ref returns a reference to the component schema of a message or an enum,
+// queuing the schema the first time it is referenced.
+func (g *generator) ref(name protoreflect.FullName, x interface{}) *schema {
+	if !g.referenced[name] {
+		g.referenced[name] = true
+		g.pending = append(g.pending, x)
+	}
+
+	return &schema{Ref: schemaRefPrefix + string(name)}
+}
+
+func (g *generator) errorRef() *schema {
+	if g.opts.StatusErrors {
+		return &schema{Ref: schemaRefPrefix + statusSchemaName}
+	}
+
+	return &schema{Ref: schemaRefPrefix + errorSchemaName}
+}
+
+// addErrorSchemas adds the schema of the error body, the one of
+// gohttp.DefaultErrorEncoder or gohttp.StatusErrorEncoder.
+func (g *generator) addErrorSchemas() {
+	details := &schema{
+		Type:        "array",
+		Description: "Typed details of the error, such as google.rpc.BadRequest or google.rpc.ErrorInfo.",
+		Items:       &schema{Ref: schemaRefPrefix + anySchemaName},
+	}
+
+	if g.opts.StatusErrors {
+		g.doc.Components.Schemas.set(statusSchemaName, &schema{
+			Type: "object",
+			Properties: properties(
+				"code", &schema{Type: "integer", Format: "int32", Description: "The google.rpc.Code of the error."},
+				"message", &schema{Type: "string"},
+				"details", details,
+			),
+		})
+	} else {
+		g.doc.Components.Schemas.set(errorSchemaName, &schema{
+			Type: "object",
+			Properties: properties(
+				"message", &schema{Type: "string"},
+				"data", &schema{Description: "Data attached to the error by the server."},
+				"reason", &schema{Type: "string", Description: "The stable reason of the error, such as USER_NOT_FOUND."},
+				"code", &schema{Type: "integer", Format: "int32", Description: "The google.rpc.Code of the error."},
+				"details", details,
+			),
+		})
+	}
+
+	g.doc.Components.Schemas.set(anySchemaName, &schema{
+		Type:                 "object",
+		Properties:           properties("@type", &schema{Type: "string"}),
+		AdditionalProperties: &schema{},
+	})
+}
+
+// wellKnownSchema returns the schema of the protojson form of the well-known
+// types, which are not encoded as objects.
+func wellKnownSchema(name protoreflect.FullName) (*schema, bool) {
+	switch name {
+	case "google.protobuf.Timestamp":
+		return &schema{Type: "string", Format: "date-time"}, true
+	case "google.protobuf.Duration":
+		return &schema{Type: "string", Pattern: `^-?[0-9]+(\.[0-9]+)?s$`}, true
+	case "google.protobuf.FieldMask":
+		return &schema{Type: "string", Format: "field-mask"}, true
+	case "google.protobuf.DoubleValue":
+		return &schema{Type: "number", Format: "double"}, true
+	case "google.protobuf.FloatValue":
+		return &schema{Type: "number", Format: "float"}, true
+	case "google.protobuf.Int64Value":
+		return &schema{Type: "string", Format: "int64"}, true
+	case "google.protobuf.UInt64Value":
+		return &schema{Type: "string", Format: "uint64"}, true
+	case "google.protobuf.Int32Value":
+		return &schema{Type: "integer", Format: "int32"}, true
+	case "google.protobuf.UInt32Value":
+		return &schema{Type: "integer", Format: "uint32"}, true
+	case "google.protobuf.BoolValue":
+		return &schema{Type: "boolean"}, true
+	case "google.protobuf.StringValue":
+		return &schema{Type: "string"}, true
+	case "google.protobuf.BytesValue":
+		return &schema{Type: "string", Format: "byte"}, true
+	case "google.protobuf.Struct", "google.protobuf.Empty":
+		return &schema{Type: "object"}, true
+	case "google.protobuf.ListValue":
+		return &schema{Type: "array", Items: &schema{}}, true
+	case "google.protobuf.Value":
+		return &schema{}, true
+	case "google.protobuf.Any":
+		return &schema{Ref: schemaRefPrefix + anySchemaName}, true
+	default:
+		return nil, false
+	}
+}
+
+// isHttpBody reports whether msg is a google.api.HttpBody, which is sent as the
+// raw body with its own content type.
+func isHttpBody(msg *protogen.Message) bool {
+	return msg != nil && msg.Desc.FullName() == httpBodyName
+}
+
+// responseMessage returns the message of the response body of a route, nil
+// when the response_body field is not a singular message.
+func responseMessage(route *httproute.Route) *protogen.Message {
+	if route.ResponseBody == "" {
+		return route.Method.Output
+	}
+
+	field := httproute.FindField(route.Method.Output, route.ResponseBody)
+	if field.Desc.IsList() {
+		return nil
+	}
+	return field.Message
+}
+
+func binarySchema() *schema {
+	return &schema{Type: "string", Format: "binary"}
+}
+
+// resolveField resolves a dotted field path checked by httproute.
+func resolveField(msg *protogen.Message, fieldPath string) *protogen.Field {
+	var field *protogen.Field
+	for _, name := range strings.Split(fieldPath, ".") {
+		field = httproute.FindField(msg, name)
+		msg = field.Message
+	}
+
+	return field
+}
+
+// variableAt returns the variable of tmpl starting at segment i.
+func variableAt(tmpl *httprule.Template, i int) (httprule.Variable, bool) {
+	for _, v := range tmpl.Variables {
+		if v.Start == i {
+			return v, true
+		}
+	}
+
+	return httprule.Variable{}, false
+}
+
+// segmentsPattern returns the regular expression matching the value of
+// template segments, "*" matching a segment and "**" any number of them.
+func segmentsPattern(segments []httprule.Segment) string {
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		switch seg.Kind {
+		case httprule.SegmentLiteral:
+			parts = append(parts, regexp.QuoteMeta(seg.Literal))
+		case httprule.SegmentWildcard:
+			parts = append(parts, "[^/]+")
+		case httprule.SegmentDeepWildcard:
+			parts = append(parts, ".+")
+		}
+	}
+
+	return "^" + strings.Join(parts, "/") + "$"
+}
+
+// operationKey returns the key of an operation in its path item. OpenAPI 3.1
+// has no field for the methods of custom rules, which are documented under an
+// extension instead.
+func operationKey(httpMethod string) string {
+	switch httpMethod {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+		http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace:
+		return strings.ToLower(httpMethod)
+	default:
+		return "x-" + strings.ToLower(httpMethod)
+	}
+}
+
+// description returns the text of a proto comment.
+func description(c protogen.Comments) string {
+	lines := strings.Split(strings.TrimSuffix(string(c), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func content(kv ...interface{}) *orderedMap[*mediaType] {
+	m := newOrderedMap[*mediaType]()
+	for i := 0; i < len(kv); i += 2 {
+		m.set(kv[i].(string), &mediaType{Schema: kv[i+1].(*schema)})
+	}
+
+	return m
+}
+
+func properties(kv ...interface{}) *orderedMap[*schema] {
+	m := newOrderedMap[*schema]()
+	for i := 0; i < len(kv); i += 2 {
+		m.set(kv[i].(string), kv[i+1].(*schema))
+	}
+
+	return m
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/internal/httproute"
+	"github.com/getfrontierhq/buf-public-apis/internal/plugintest"
+	"google.golang.org/protobuf/compiler/protogen"
+	"gopkg.in/yaml.v3"
+)
+
+const libraryProto = `
+name: "example/v1/library.proto"
+package: "example.v1"
+dependency: "google/api/annotations.proto"
+options { go_package: "example.com/example/v1;examplev1" }
+message_type {
+  name: "Book"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+  field { name: "title" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "title" }
+}
+message_type {
+  name: "GetBookRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+  field { name: "view" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "view" }
+}
+message_type {
+  name: "CreateBookRequest"
+  field { name: "parent" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "parent" }
+  field { name: "book" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".example.v1.Book" json_name: "book" }
+}
+service {
+  name: "Library"
+  method {
+    name: "GetBook"
+    input_type: ".example.v1.GetBookRequest"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { get: "/v1/{name=shelves/*/books/*}" } }
+  }
+  method {
+    name: "CreateBook"
+    input_type: ".example.v1.CreateBookRequest"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { post: "/v1/{parent=shelves/*}/books" body: "book" } }
+  }
+}
+syntax: "proto3"
+`
+
+// generate runs generateFile on the file described by content and returns the
+// documents it generates, decoded.
+func generate(t *testing.T, content string, opts options) map[string]interface{} {
+	files := plugintest.Run(t, content, func(gen *protogen.Plugin) error {
+		for _, f := range gen.Files {
+			if f.Generate {
+				generateFile(gen, f, opts)
+			}
+		}
+		return nil
+	})
+
+	docs := make(map[string]interface{})
+	for name, content := range files {
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		docs[name] = doc
+	}
+
+	return docs
+}
+
+// lookup returns the value at path in a decoded document, a path element
+// being a key of a map or the index of a list.
+func lookup(t *testing.T, v interface{}, path ...interface{}) interface{} {
+	t.Helper()
+
+	for i, key := range path {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			v = x[fmt.Sprint(key)]
+		case []interface{}:
+			idx, ok := key.(int)
+			if !ok || idx >= len(x) {
+				t.Fatalf("no %v in %v", path[:i+1], x)
+			}
+			v = x[idx]
+		default:
+			t.Fatalf("no %v in %v", path[:i+1], v)
+		}
+		if v == nil {
+			t.Fatalf("no %v", path[:i+1])
+		}
+	}
+
+	return v
+}
+
+func TestGenerateFile(t *testing.T) {
+	opts := options{Routes: httproute.Options{Omitempty: true}, APIVersion: "1.0.0"}
+
+	for _, format := range []string{"yaml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			opts.Format = format
+			docs := generate(t, libraryProto, opts)
+
+			name := "example/v1/library.Library.openapi." + format
+			doc, ok := docs[name]
+			if !ok || len(docs) != 1 {
+				t.Fatalf("got files %v, want %s", docs, name)
+			}
+
+			tests := []struct {
+				path []interface{}
+				want interface{}
+			}{
+				{path: []interface{}{"openapi"}, want: "3.1.0"},
+				{path: []interface{}{"info", "title"}, want: "example.v1.Library"},
+				{path: []interface{}{"paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "operationId"}, want: "Library_GetBook"},
+				{path: []interface{}{"paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "parameters", 0, "name"}, want: "name.shelves"},
+				{path: []interface{}{"paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "parameters", 0, "in"}, want: "path"},
+				{path: []interface{}{"paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "parameters", 1, "schema", "pattern"}, want: "^[^/]+$"},
+				{path: []interface{}{"paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "parameters", 2, "name"}, want: "view"},
+				{path: []interface{}{"paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "parameters", 2, "in"}, want: "query"},
+				{path: []interface{}{"paths", "/v1/shelves/{parent.shelves}/books", "post", "requestBody", "content", "application/json", "schema", "$ref"}, want: "#/components/schemas/example.v1.Book"},
+				{path: []interface{}{"paths", "/v1/shelves/{parent.shelves}/books", "post", "responses", "default", "content", "application/json", "schema", "$ref"}, want: "#/components/schemas/gohttp.Error"},
+				{path: []interface{}{"components", "schemas", "example.v1.Book", "properties", "title", "type"}, want: "string"},
+			}
+			for _, tt := range tests {
+				if got := lookup(t, doc, tt.path...); got != tt.want {
+					t.Errorf("got %v at %v, want %v", got, tt.path, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFileStatusErrors(t *testing.T) {
+	docs := generate(t, libraryProto, options{Routes: httproute.Options{Omitempty: true}, Format: "yaml", StatusErrors: true})
+
+	doc := docs["example/v1/library.Library.openapi.yaml"]
+	ref := lookup(t, doc, "paths", "/v1/shelves/{name.shelves}/books/{name.books}", "get", "responses", "default", "content", "application/json", "schema", "$ref")
+	if ref != "#/components/schemas/google.rpc.Status" {
+		t.Fatalf("got error schema %v, want google.rpc.Status", ref)
+	}
+	lookup(t, doc, "components", "schemas", "google.rpc.Status", "properties", "code")
+}
+
+func TestGenerateFileAdditionalBindings(t *testing.T) {
+	content := strings.Replace(libraryProto,
+		`get: "/v1/{name=shelves/*/books/*}"`,
+		`get: "/v1/{name=shelves/*/books/*}" additional_bindings { get: "/v1/{name=authors/*/books/*}" } additional_bindings { get: "/v1/{name}" }`, 1)
+	docs := generate(t, content, options{Routes: httproute.Options{Omitempty: true}, Format: "yaml"})
+	doc := docs["example/v1/library.Library.openapi.yaml"]
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/v1/shelves/{name.shelves}/books/{name.books}", want: "Library_GetBook"},
+		{path: "/v1/authors/{name.authors}/books/{name.books}", want: "Library_GetBook_1"},
+		{path: "/v1/{name}", want: "Library_GetBook_2"},
+	}
+	for _, tt := range tests {
+		if got := lookup(t, doc, "paths", tt.path, "get", "operationId"); got != tt.want {
+			t.Errorf("got operation %v at %s, want %s", got, tt.path, tt.want)
+		}
+	}
+}
+
+const filesProto = `
+name: "example/v1/files.proto"
+package: "example.v1"
+dependency: "google/api/annotations.proto"
+dependency: "google/api/httpbody.proto"
+options { go_package: "example.com/example/v1;examplev1" }
+message_type {
+  name: "UploadFileRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+  field { name: "file" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.api.HttpBody" json_name: "file" }
+}
+message_type {
+  name: "DownloadFileRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+}
+service {
+  name: "Files"
+  method {
+    name: "UploadFile"
+    input_type: ".example.v1.UploadFileRequest"
+    output_type: ".google.api.HttpBody"
+    options { [google.api.http] { put: "/v1/files/{name}" body: "file" } }
+  }
+  method {
+    name: "DownloadFile"
+    input_type: ".example.v1.DownloadFileRequest"
+    output_type: ".google.api.HttpBody"
+    options { [google.api.http] { get: "/v1/files/{name}:download" } }
+    server_streaming: true
+  }
+}
+syntax: "proto3"
+`
+
+func TestGenerateFileHttpBody(t *testing.T) {
+	docs := generate(t, filesProto, options{Routes: httproute.Options{Omitempty: true}, Format: "yaml"})
+	doc := docs["example/v1/files.Files.openapi.yaml"]
+
+	tests := []struct {
+		name string
+		path []interface{}
+	}{
+		{name: "request body field", path: []interface{}{"paths", "/v1/files/{name}", "put", "requestBody", "content", "*/*", "schema"}},
+		{name: "response", path: []interface{}{"paths", "/v1/files/{name}", "put", "responses", "200", "content", "*/*", "schema"}},
+		{name: "stream", path: []interface{}{"paths", "/v1/files/{name}:download", "get", "responses", "200", "content", "*/*", "schema"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := lookup(t, doc, tt.path...)
+			if lookup(t, s, "type") != "string" || lookup(t, s, "format") != "binary" {
+				t.Fatalf("got schema %v, want a binary string", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The subset of the OpenAPI 3.1 document model the plugin generates.
+type (
+	document struct {
+		OpenAPI    string                               `json:"openapi" yaml:"openapi"`
+		Info       info                                 `json:"info" yaml:"info"`
+		Tags       []tag                                `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Paths      *orderedMap[*orderedMap[*operation]] `json:"paths" yaml:"paths"`
+		Components components                           `json:"components" yaml:"components"`
+	}
+
+	info struct {
+		Title       string `json:"title" yaml:"title"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+		Version     string `json:"version" yaml:"version"`
+	}
+
+	tag struct {
+		Name        string `json:"name" yaml:"name"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	}
+
+	components struct {
+		Schemas *orderedMap[*schema] `json:"schemas" yaml:"schemas"`
+	}
+
+	operation struct {
+		OperationID string                 `json:"operationId" yaml:"operationId"`
+		Description string                 `json:"description,omitempty" yaml:"description,omitempty"`
+		Tags        []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Deprecated  bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+		Parameters  []*parameter           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+		RequestBody *requestBody           `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+		Responses   *orderedMap[*response] `json:"responses" yaml:"responses"`
+	}
+
+	parameter struct {
+		Name        string  `json:"name" yaml:"name"`
+		In          string  `json:"in" yaml:"in"`
+		Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+		Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+		Deprecated  bool    `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+		Style       string  `json:"style,omitempty" yaml:"style,omitempty"`
+		Explode     bool    `json:"explode,omitempty" yaml:"explode,omitempty"`
+		Schema      *schema `json:"schema" yaml:"schema"`
+	}
+
+	requestBody struct {
+		Description string                  `json:"description,omitempty" yaml:"description,omitempty"`
+		Required    bool                    `json:"required,omitempty" yaml:"required,omitempty"`
+		Content     *orderedMap[*mediaType] `json:"content" yaml:"content"`
+	}
+
+	response struct {
+		Description string                  `json:"description" yaml:"description"`
+		Content     *orderedMap[*mediaType] `json:"content,omitempty" yaml:"content,omitempty"`
+	}
+
+	mediaType struct {
+		Schema *schema `json:"schema" yaml:"schema"`
+	}
+
+	schema struct {
+		Ref                  string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+		Type                 string               `json:"type,omitempty" yaml:"type,omitempty"`
+		Format               string               `json:"format,omitempty" yaml:"format,omitempty"`
+		Description          string               `json:"description,omitempty" yaml:"description,omitempty"`
+		Pattern              string               `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+		Enum                 []string             `json:"enum,omitempty" yaml:"enum,omitempty"`
+		Items                *schema              `json:"items,omitempty" yaml:"items,omitempty"`
+		Properties           *orderedMap[*schema] `json:"properties,omitempty" yaml:"properties,omitempty"`
+		AdditionalProperties *schema              `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+		Required             []string             `json:"required,omitempty" yaml:"required,omitempty"`
+		ReadOnly             bool                 `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+		WriteOnly            bool                 `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+		Deprecated           bool                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	}
+)
+
+// orderedMap is a JSON object or YAML mapping keeping its keys in insertion
+// order, so that paths and properties follow the order of the proto file.
+type orderedMap[V any] struct {
+	keys   []string
+	values map[string]V
+}
+
+func newOrderedMap[V any]() *orderedMap[V] {
+	return &orderedMap[V]{values: make(map[string]V)}
+}
+
+func (m *orderedMap[V]) get(key string) (V, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *orderedMap[V]) set(key string, v V) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap[V]) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (m *orderedMap[V]) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range m.keys {
+		v := new(yaml.Node)
+		if err := v.Encode(m.values[key]); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	}
+
+	return node, nil
+}
@@ -0,0 +1,3 @@
+package main
+
+const release = "v0.0.1"
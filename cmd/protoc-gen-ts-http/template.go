@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"strings"
+	"text/template"
+)
+
+//go:embed tsTemplate.tpl
+var tsTemplate string
+
+type fileDescriptor struct {
+	Types    []string // interface and enum declarations
+	Services []*serviceDescriptor
+
+	// the runtime helpers used by the clients.
+	UsesReadBody        bool
+	UsesReadStream      bool
+	UsesRawBody         bool
+	UsesReadRawBody     bool
+	UsesReadRawStream   bool
+	UsesExpandVariable  bool
+	UsesUnnamedWildcard bool
+	UsesAppendQuery     bool
+	UsesAppendQueryMap  bool
+}
+
+type serviceDescriptor struct {
+	ServiceType string // Greeter
+	Comment     string
+	Methods     []*methodDescriptor
+}
+
+type methodDescriptor struct {
+	// method
+	Name    string // sayHello
+	Request string
+	Reply   string
+	Comment string
+
+	// http_rule, as TypeScript expressions
+	Method           string
+	Path             string
+	Query            []*queryDescriptor
+	Body             string
+	ResponseBody     string // the JSON name of the response_body field
+	ResponseBodyType string
+
+	// google.api.HttpBody, sent as the raw body with its own content type
+	RawBody     bool
+	RawResponse bool
+
+	// streaming
+	ServerStreaming bool
+}
+
+type queryDescriptor struct {
+	Key   string // filter.query
+	Value string // req.filter?.query
+	Map   bool
+}
+
+func (f *fileDescriptor) execute() string {
+	for _, s := range f.Services {
+		for _, m := range s.Methods {
+			f.UsesReadBody = f.UsesReadBody || !m.ServerStreaming && !m.RawResponse
+			f.UsesReadStream = f.UsesReadStream || m.ServerStreaming && !m.RawResponse
+			f.UsesRawBody = f.UsesRawBody || m.RawBody
+			f.UsesReadRawBody = f.UsesReadRawBody || !m.ServerStreaming && m.RawResponse
+			f.UsesReadRawStream = f.UsesReadRawStream || m.ServerStreaming && m.RawResponse
+			f.UsesExpandVariable = f.UsesExpandVariable || strings.Contains(m.Path, "expandVariable(")
+			f.UsesUnnamedWildcard = f.UsesUnnamedWildcard || strings.Contains(m.Path, "unnamedWildcard(")
+			for _, q := range m.Query {
+				f.UsesAppendQuery = f.UsesAppendQuery || !q.Map
+				f.UsesAppendQueryMap = f.UsesAppendQueryMap || q.Map
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	tmpl, err := template.New("ts").Parse(strings.TrimSpace(tsTemplate))
+	if err != nil {
+		panic(err)
+	}
+
+	if err := tmpl.Execute(buf, f); err != nil {
+		panic(err)
+	}
+
+	return strings.Trim(buf.String(), "\r\n")
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/getfrontierhq/buf-public-apis/internal/httproute"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+const httpBodyName protoreflect.FullName = "google.api.HttpBody"
+
+// generateFile generates a _http.pb.ts file.
+func generateFile(gen *protogen.Plugin, file *protogen.File, omitempty bool, omitemptyPrefix string) *protogen.GeneratedFile {
+	if len(file.Services) == 0 || (omitempty && !httproute.HasHTTPRule(file.Services, false)) {
+		return nil
+	}
+
+	types := newTypeSet()
+	fileDesc := &fileDescriptor{}
+	for _, service := range file.Services {
+		routes := httproute.ServiceRoutes(gen, service, httproute.Options{
+			Omitempty:       omitempty,
+			OmitemptyPrefix: omitemptyPrefix,
+		})
+
+		if serviceDesc := buildService(types, service, routes); len(serviceDesc.Methods) != 0 {
+			fileDesc.Services = append(fileDesc.Services, serviceDesc)
+		}
+	}
+
+	if len(fileDesc.Services) == 0 {
+		return nil
+	}
+	fileDesc.Types = types.declarations()
+
+	// the output mirrors the proto path, go_package being meaningless here.
+	filename := strings.TrimSuffix(file.Desc.Path(), ".proto") + "_http.pb.ts"
+	g := gen.NewGeneratedFile(filename, "")
+	g.P("// Code generated by protoc-gen-ts-http. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P(fmt.Sprintf("// - protoc-gen-ts-http %s", release))
+	g.P("// - protoc             ", protocVersion(gen))
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("/* eslint-disable */")
+	g.P()
+	g.P(fileDesc.execute())
+
+	return g
+}
+
+// buildService builds the client of service. Like the Go client, a method is
+// called on its main rule, the last of its routes.
+func buildService(types *typeSet, service *protogen.Service, routes []*httproute.Route) *serviceDescriptor {
+	serviceDesc := &serviceDescriptor{
+		ServiceType: service.GoName,
+		Comment:     jsDoc(service.Comments.Leading, service.Desc.Options().(*descriptorpb.ServiceOptions).GetDeprecated(), ""),
+	}
+
+	mainRoutes := make(map[*protogen.Method]*httproute.Route)
+	for _, route := range routes {
+		mainRoutes[route.Method] = route
+	}
+
+	for _, method := range service.Methods {
+		route, ok := mainRoutes[method]
+		if !ok || method.Desc.IsStreamingClient() {
+			continue
+		}
+
+		serviceDesc.Methods = append(serviceDesc.Methods, buildMethodDesc(types, route))
+	}
+
+	return serviceDesc
+}
+
+func buildMethodDesc(types *typeSet, route *httproute.Route) *methodDescriptor {
+	m := route.Method
+	methodDesc := &methodDescriptor{
+		Name:            unexport(m.GoName),
+		Request:         types.messageType(m.Input),
+		Reply:           types.messageType(m.Output),
+		Comment:         jsDoc(m.Comments.Leading, m.Desc.Options().(*descriptorpb.MethodOptions).GetDeprecated(), "  "),
+		Method:          route.HTTPMethod,
+		Path:            pathExpr(route),
+		ServerStreaming: m.Desc.IsStreamingServer(),
+	}
+
+	for _, qf := range route.QueryFields() {
+		methodDesc.Query = append(methodDesc.Query, &queryDescriptor{
+			Key:   qf.Name,
+			Value: accessor(qf.Fields),
+			Map:   qf.Field().Desc.IsMap(),
+		})
+	}
+
+	switch route.Body {
+	case "":
+	case httproute.BodyWildcard:
+		methodDesc.Body = "req"
+		methodDesc.RawBody = isHttpBody(m.Input)
+	default:
+		// an unset body field is sent as its zero value, like the Go client.
+		field := httproute.FindField(m.Input, route.Body)
+		zero := "null"
+		if field.Message != nil || field.Desc.IsList() {
+			zero = "{}"
+			if field.Desc.IsList() {
+				zero = "[]"
+			}
+		}
+		methodDesc.Body = fmt.Sprintf("%s ?? %s", accessor([]*protogen.Field{field}), zero)
+		methodDesc.RawBody = !field.Desc.IsList() && isHttpBody(field.Message)
+	}
+
+	methodDesc.RawResponse = isHttpBody(m.Output)
+	if route.ResponseBody != "" {
+		field := httproute.FindField(m.Output, route.ResponseBody)
+		name := field.Desc.JSONName()
+		methodDesc.ResponseBody = propertyName(name)
+		methodDesc.ResponseBodyType = fmt.Sprintf("%s[%s]", methodDesc.Reply, quote(name))
+		methodDesc.RawResponse = !field.Desc.IsList() && isHttpBody(field.Message)
+	}
+
+	return methodDesc
+}
+
+// pathExpr returns the expression building the request path of route,
+// expanding its variables with the runtime expandVariable.
+func pathExpr(route *httproute.Route) string {
+	tmpl := route.Template
+
+	var (
+		parts   []string
+		literal string
+	)
+	flush := func() {
+		if literal != "" {
+			parts = append(parts, quote(literal))
+			literal = ""
+		}
+	}
+
+	for i := 0; i < len(tmpl.Segments); i++ {
+		if v, ok := variableAt(tmpl, i); ok {
+			segments := make([]string, 0, v.End-v.Start)
+			for _, seg := range tmpl.Segments[v.Start:v.End] {
+				segments = append(segments, quote(segmentString(seg)))
+			}
+
+			flush()
+			parts = append(parts, fmt.Sprintf("expandVariable([%s], %s, %s)", strings.Join(segments, ", "), quote(v.FieldPath), accessor(resolveFields(route.Method.Input, v.FieldPath))))
+			i = v.End - 1
+			continue
+		}
+
+		seg := tmpl.Segments[i]
+		if seg.Kind != httprule.SegmentLiteral {
+			// the Go client cannot expand an unnamed wildcard either.
+			return fmt.Sprintf("unnamedWildcard(%s)", quote(tmpl.Template))
+		}
+		literal += "/" + seg.Literal
+	}
+
+	if tmpl.Verb != "" {
+		literal += ":" + tmpl.Verb
+	}
+	flush()
+
+	if len(parts) == 0 {
+		return quote("/")
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+// isHttpBody reports whether msg is a google.api.HttpBody, which is sent as the
+// raw body with its own content type.
+func isHttpBody(msg *protogen.Message) bool {
+	return msg != nil && msg.Desc.FullName() == httpBodyName
+}
+
+// accessor returns the expression reading a field path of the request, the
+// fields being named by their JSON names.
+func accessor(fields []*protogen.Field) string {
+	var b strings.Builder
+	b.WriteString("req")
+	for i, field := range fields {
+		name := field.Desc.JSONName()
+		switch {
+		case i > 0 && identifier.MatchString(name):
+			b.WriteString("?." + name)
+		case i > 0:
+			b.WriteString("?.[" + quote(name) + "]")
+		case identifier.MatchString(name):
+			b.WriteString("." + name)
+		default:
+			b.WriteString("[" + quote(name) + "]")
+		}
+	}
+
+	return b.String()
+}
+
+// typeSet names the TypeScript declarations of the messages and enums used by
+// the clients of a file, collecting the ones they reference.
+type typeSet struct {
+	names   map[protoreflect.FullName]string
+	taken   map[string]bool
+	pending []interface{} // *protogen.Message or *protogen.Enum
+}
+
+func newTypeSet() *typeSet {
+	return &typeSet{
+		names: make(map[protoreflect.FullName]string),
+		// the names declared by the template.
+		taken: map[string]bool{
+			"ClientOptions": true,
+			"Code":          true,
+			"ErrorDetail":   true,
+			"HTTPError":     true,
+			"ErrorBody":     true,
+		},
+	}
+}
+
+func (t *typeSet) messageType(msg *protogen.Message) string {
+	if typ, ok := wellKnownType(msg.Desc.FullName()); ok {
+		return typ
+	}
+
+	return t.name(msg.Desc.FullName(), msg.GoIdent.GoName, msg)
+}
+
+// name returns the declared name of a message or an enum, queuing its
+// declaration the first time it is referenced. Names are the Go names, the
+// full name being used instead on a collision between packages.
+func (t *typeSet) name(fullName protoreflect.FullName, goName string, x interface{}) string {
+	if name, ok := t.names[fullName]; ok {
+		return name
+	}
+
+	name := goName
+	if t.taken[name] {
+		name = strings.ReplaceAll(string(fullName), ".", "_")
+	}
+
+	t.names[fullName] = name
+	t.taken[name] = true
+	t.pending = append(t.pending, x)
+
+	return name
+}
+
+// declarations returns the declarations of the referenced types, including
+// the ones referenced by their fields.
+func (t *typeSet) declarations() []string {
+	var decls []string
+	for i := 0; i < len(t.pending); i++ {
+		switch x := t.pending[i].(type) {
+		case *protogen.Message:
+			decls = append(decls, t.interfaceDecl(x))
+		case *protogen.Enum:
+			decls = append(decls, t.enumDecl(x))
+		}
+	}
+
+	return decls
+}
+
+func (t *typeSet) interfaceDecl(msg *protogen.Message) string {
+	var b strings.Builder
+	if doc := jsDoc(msg.Comments.Leading, msg.Desc.Options().(*descriptorpb.MessageOptions).GetDeprecated(), ""); doc != "" {
+		b.WriteString(doc + "\n")
+	}
+
+	fmt.Fprintf(&b, "export interface %s {", t.names[msg.Desc.FullName()])
+	for _, field := range msg.Fields {
+		b.WriteString("\n")
+		if doc := jsDoc(field.Comments.Leading, field.Desc.Options().(*descriptorpb.FieldOptions).GetDeprecated(), "  "); doc != "" {
+			b.WriteString(doc + "\n")
+		}
+		fmt.Fprintf(&b, "  %s?: %s;", propertyName(field.Desc.JSONName()), t.fieldType(field))
+	}
+	if len(msg.Fields) != 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+func (t *typeSet) enumDecl(enum *protogen.Enum) string {
+	var b strings.Builder
+	if doc := jsDoc(enum.Comments.Leading, enum.Desc.Options().(*descriptorpb.EnumOptions).GetDeprecated(), ""); doc != "" {
+		b.WriteString(doc + "\n")
+	}
+
+	fmt.Fprintf(&b, "export type %s =", t.names[enum.Desc.FullName()])
+	for _, v := range enum.Values {
+		fmt.Fprintf(&b, "\n  | %s", quote(string(v.Desc.Name())))
+	}
+	b.WriteString(";")
+
+	return b.String()
+}
+
+// fieldType returns the type of the protojson form of field.
+func (t *typeSet) fieldType(field *protogen.Field) string {
+	switch {
+	case field.Desc.IsMap():
+		return fmt.Sprintf("{ [key: string]: %s }", t.kindType(field.Message.Fields[1]))
+	case field.Desc.IsList():
+		typ := t.kindType(field)
+		if strings.ContainsAny(typ, " |") {
+			typ = "(" + typ + ")"
+		}
+		return typ + "[]"
+	default:
+		return t.kindType(field)
+	}
+}
+
+// kindType returns the type of a single value of field.
+func (t *typeSet) kindType(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "boolean"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "number"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson encodes 64-bit integers as strings.
+		return "string"
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return "string"
+	case protoreflect.EnumKind:
+		if field.Enum.Desc.FullName() == "google.protobuf.NullValue" {
+			return "null"
+		}
+		return t.name(field.Enum.Desc.FullName(), field.Enum.GoIdent.GoName, field.Enum)
+	default:
+		return t.messageType(field.Message)
+	}
+}
+
+// wellKnownType returns the type of the protojson form of the well-known
+// types, which are not encoded as objects.
+func wellKnownType(name protoreflect.FullName) (string, bool) {
+	switch name {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return "string", true
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return "number", true
+	case "google.protobuf.BoolValue":
+		return "boolean", true
+	case "google.protobuf.Struct":
+		return "{ [key: string]: unknown }", true
+	case "google.protobuf.Value":
+		return "unknown", true
+	case "google.protobuf.ListValue":
+		return "unknown[]", true
+	case "google.protobuf.Empty":
+		return "Record<string, never>", true
+	case "google.protobuf.Any":
+		return "ErrorDetail", true
+	default:
+		return "", false
+	}
+}
+
+// resolveFields resolves a dotted field path checked by httproute.
+func resolveFields(msg *protogen.Message, fieldPath string) []*protogen.Field {
+	var fields []*protogen.Field
+	for _, name := range strings.Split(fieldPath, ".") {
+		field := httproute.FindField(msg, name)
+		fields = append(fields, field)
+		msg = field.Message
+	}
+
+	return fields
+}
+
+// variableAt returns the variable of tmpl starting at segment i.
+func variableAt(tmpl *httprule.Template, i int) (httprule.Variable, bool) {
+	for _, v := range tmpl.Variables {
+		if v.Start == i {
+			return v, true
+		}
+	}
+
+	return httprule.Variable{}, false
+}
+
+func segmentString(seg httprule.Segment) string {
+	switch seg.Kind {
+	case httprule.SegmentWildcard:
+		return "*"
+	case httprule.SegmentDeepWildcard:
+		return "**"
+	default:
+		return seg.Literal
+	}
+}
+
+// jsDoc formats a proto comment as a JSDoc comment, indented by indent.
+func jsDoc(c protogen.Comments, deprecated bool, indent string) string {
+	var lines []string
+	if text := strings.TrimSpace(string(c)); text != "" {
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimPrefix(line, " "))
+		}
+	}
+	if deprecated {
+		lines = append(lines, "@deprecated")
+	}
+
+	switch len(lines) {
+	case 0:
+		return ""
+	case 1:
+		return indent + "/** " + escapeComment(lines[0]) + " */"
+	}
+
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight(indent+" * "+escapeComment(line), " ") + "\n")
+	}
+	b.WriteString(indent + " */")
+
+	return b.String()
+}
+
+func escapeComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "*\\/")
+}
+
+func propertyName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+
+	return quote(name)
+}
+
+// quote returns the JavaScript literal of v.
+func quote(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(b)
+}
+
+// unexport lowercases the first letter of an identifier.
+func unexport(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+func protocVersion(gen *protogen.Plugin) string {
+	v := gen.Request.GetCompilerVersion()
+	if v == nil {
+		return "(unknown)"
+	}
+
+	var suffix string
+	if s := v.GetSuffix(); s != "" {
+		suffix = "-" + s
+	}
+
+	return fmt.Sprintf("v%d.%d.%d%s", v.GetMajor(), v.GetMinor(), v.GetPatch(), suffix)
+}
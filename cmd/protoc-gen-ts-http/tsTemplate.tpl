@@ -0,0 +1,397 @@
+{{- range .Types}}
+{{.}}
+{{end}}
+/** Options of the generated clients. */
+export interface ClientOptions {
+  /** The URL the request paths are resolved against, such as https://api.example.com. */
+  baseURL: string;
+  /** The fetch implementation, globalThis.fetch by default. */
+  fetch?: typeof fetch;
+  /** Headers sent with every request. */
+  headers?: Record<string, string>;
+}
+
+/** The google.rpc.Code of an error. */
+export const Code = {
+  OK: 0,
+  CANCELLED: 1,
+  UNKNOWN: 2,
+  INVALID_ARGUMENT: 3,
+  DEADLINE_EXCEEDED: 4,
+  NOT_FOUND: 5,
+  ALREADY_EXISTS: 6,
+  PERMISSION_DENIED: 7,
+  RESOURCE_EXHAUSTED: 8,
+  FAILED_PRECONDITION: 9,
+  ABORTED: 10,
+  OUT_OF_RANGE: 11,
+  UNIMPLEMENTED: 12,
+  INTERNAL: 13,
+  UNAVAILABLE: 14,
+  DATA_LOSS: 15,
+  UNAUTHENTICATED: 16,
+} as const;
+
+/** A typed error detail, such as a google.rpc.BadRequest. */
+export interface ErrorDetail {
+  "@type": string;
+  [key: string]: unknown;
+}
+
+/**
+ * HTTPError is the error reported by the server, decoded from an errors.Error
+ * or a google.rpc.Status body.
+ */
+export class HTTPError extends Error {
+  /** The HTTP status of the response. */
+  readonly status: number;
+  /** The google.rpc.Code of the error, derived from the status when the body carries none. */
+  readonly code: number;
+  /** The stable reason of the error, such as USER_NOT_FOUND. */
+  readonly reason?: string;
+  readonly data?: unknown;
+  readonly details: ErrorDetail[];
+
+  constructor(status: number, body: ErrorBody) {
+    super(body.message ?? "");
+    this.name = "HTTPError";
+    this.status = status;
+    this.code = body.code ?? codeFromHTTPStatus(status);
+    this.data = body.data;
+    this.details = body.details ?? [];
+    this.reason = body.reason ?? errorInfoReason(this.details);
+  }
+}
+{{- range .Services}}
+{{- $svcType := .ServiceType}}
+{{if ne .Comment ""}}
+{{.Comment}}
+{{- end}}
+export class {{$svcType}}HTTPClient {
+  private readonly options: ClientOptions;
+
+  constructor(options: ClientOptions) {
+    this.options = options;
+  }
+{{- range .Methods}}
+{{if ne .Comment ""}}
+{{.Comment}}
+{{- end}}
+{{- if .ServerStreaming}}
+  async *{{.Name}}(req: {{.Request}}, init?: RequestInit): AsyncGenerator<{{.Reply}}> {
+{{- else}}
+  async {{.Name}}(req: {{.Request}}, init?: RequestInit): Promise<{{.Reply}}> {
+{{- end}}
+    const query = new URLSearchParams();
+{{- range .Query}}
+{{- if .Map}}
+    appendQueryMap(query, "{{.Key}}", {{.Value}});
+{{- else}}
+    appendQuery(query, "{{.Key}}", {{.Value}});
+{{- end}}
+{{- end}}
+{{- if .RawBody}}
+    const [body, contentType] = rawBody({{.Body}});
+{{- end}}
+{{- if .ServerStreaming}}
+    const res = await send(this.options, "{{.Method}}", {{.Path}}, query, {{template "body" .}}, init, "{{if .RawResponse}}*/*{{else}}application/x-ndjson{{end}}"{{if .RawBody}}, contentType{{end}});
+{{- if .RawResponse}}
+    for await (const chunk of readRawStream(res)) {
+{{- if ne .ResponseBody ""}}
+      yield { {{.ResponseBody}}: chunk } as {{.Reply}};
+{{- else}}
+      yield chunk as {{.Reply}};
+{{- end}}
+    }
+{{- else}}
+    yield* readStream<{{.Reply}}>(res);
+{{- end}}
+{{- else}}
+    const res = await send(this.options, "{{.Method}}", {{.Path}}, query, {{template "body" .}}, init{{if .RawResponse}}, "*/*"{{else if .RawBody}}, "application/json"{{end}}{{if .RawBody}}, contentType{{end}});
+{{- if .RawResponse}}
+{{- if ne .ResponseBody ""}}
+    return { {{.ResponseBody}}: await readRawBody(res) } as {{.Reply}};
+{{- else}}
+    return (await readRawBody(res)) as {{.Reply}};
+{{- end}}
+{{- else if ne .ResponseBody ""}}
+    return { {{.ResponseBody}}: (await readBody(res)) as {{.ResponseBodyType}} };
+{{- else}}
+    return (await readBody(res)) as {{.Reply}};
+{{- end}}
+{{- end}}
+  }
+{{- end}}
+}
+{{- end}}
+
+interface ErrorBody {
+  message?: string;
+  code?: number;
+  reason?: string;
+  data?: unknown;
+  details?: ErrorDetail[];
+}
+
+async function send(
+  options: ClientOptions,
+  method: string,
+  path: string,
+  query: URLSearchParams,
+  body: string | Uint8Array | undefined,
+  init: RequestInit | undefined,
+  accept = "application/json",
+  contentType = "application/json",
+): Promise<Response> {
+  let url = options.baseURL.replace(/\/+$/, "") + path;
+  const rawQuery = query.toString();
+  if (rawQuery !== "") {
+    url += "?" + rawQuery;
+  }
+
+  const headers = new Headers(options.headers);
+  headers.set("Accept", accept);
+  if (body !== undefined) {
+    headers.set("Content-Type", contentType);
+  }
+  new Headers(init?.headers).forEach((value, key) => headers.set(key, value));
+
+  const res = await (options.fetch ?? globalThis.fetch)(url, { ...init, method, headers, body });
+  if (!res.ok) {
+    throw await readError(res);
+  }
+
+  return res;
+}
+
+{{- if .UsesReadBody}}
+
+async function readBody(res: Response): Promise<unknown> {
+  // a HEAD response has no body.
+  const text = await res.text();
+  return text === "" ? {} : JSON.parse(text);
+}
+{{- end}}
+
+async function readError(res: Response): Promise<HTTPError> {
+  const text = await res.text();
+  const mediaType = res.headers.get("Content-Type")?.split(";")[0].trim();
+  if (text === "" || mediaType !== "application/json") {
+    return new HTTPError(res.status, { message: res.statusText });
+  }
+
+  try {
+    return new HTTPError(res.status, JSON.parse(text) as ErrorBody);
+  } catch {
+    return new HTTPError(res.status, { message: res.statusText });
+  }
+}
+
+{{- if .UsesReadStream}}
+
+// readStream decodes the newline-delimited {"result": ...} and {"error": ...}
+// frames of a server-streaming response.
+async function* readStream<T>(res: Response): AsyncGenerator<T> {
+  if (res.body === null) {
+    return;
+  }
+
+  const reader = res.body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffered = "";
+  try {
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (value !== undefined) {
+        buffered += value;
+      }
+
+      const lines = buffered.split("\n");
+      buffered = done ? "" : lines.pop() ?? "";
+      for (const line of lines) {
+        if (line.trim() === "") {
+          continue;
+        }
+
+        const frame = JSON.parse(line) as { result?: T; error?: ErrorBody };
+        if (frame.error !== undefined) {
+          // an error sent after the first message carries the code itself.
+          throw new HTTPError(res.status, { code: Code.UNKNOWN, ...frame.error });
+        }
+        yield frame.result ?? ({} as T);
+      }
+
+      if (done) {
+        return;
+      }
+    }
+  } finally {
+    reader.releaseLock();
+  }
+}
+{{- end}}
+{{- if .UsesRawBody}}
+
+// rawBody returns the raw payload of a google.api.HttpBody and its content type.
+function rawBody(body: { contentType?: string; data?: string }): [Uint8Array, string] {
+  const binary = atob(body.data ?? "");
+  const content = new Uint8Array(binary.length);
+  for (let i = 0; i < binary.length; i++) {
+    content[i] = binary.charCodeAt(i);
+  }
+
+  return [content, body.contentType || "application/octet-stream"];
+}
+{{- end}}
+{{- if .UsesReadRawBody}}
+
+// readRawBody reads the raw body of a response as a google.api.HttpBody.
+async function readRawBody(res: Response): Promise<{ contentType: string; data: string }> {
+  return httpBody(res.headers.get("Content-Type") ?? "", new Uint8Array(await res.arrayBuffer()));
+}
+{{- end}}
+{{- if .UsesReadRawStream}}
+
+// readRawStream reads the raw body of a server-streaming response as
+// google.api.HttpBody chunks, which do not necessarily match the chunks sent by
+// the server.
+async function* readRawStream(res: Response): AsyncGenerator<{ contentType: string; data: string }> {
+  if (res.body === null) {
+    return;
+  }
+
+  const contentType = res.headers.get("Content-Type") ?? "";
+  const reader = res.body.getReader();
+  try {
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) {
+        return;
+      }
+
+      yield httpBody(contentType, value);
+    }
+  } finally {
+    reader.releaseLock();
+  }
+}
+{{- end}}
+{{- if or .UsesReadRawBody .UsesReadRawStream}}
+
+// httpBody returns a google.api.HttpBody holding data, base64-encoded like the
+// bytes fields of the JSON mapping.
+function httpBody(contentType: string, data: Uint8Array): { contentType: string; data: string } {
+  let binary = "";
+  for (let i = 0; i < data.length; i++) {
+    binary += String.fromCharCode(data[i]);
+  }
+
+  return { contentType, data: btoa(binary) };
+}
+{{- end}}
+{{- if .UsesExpandVariable}}
+
+// expandVariable escapes value as the segments of a path variable, a single "*"
+// segment accepting any value and longer patterns requiring value to match.
+function expandVariable(segments: string[], fieldPath: string, value: unknown): string {
+  const val = value === undefined || value === null ? "" : String(value);
+  if (val === "") {
+    throw new Error(`missing value for path variable "${fieldPath}"`);
+  }
+
+  if (segments.length === 1 && segments[0] === "*") {
+    return "/" + encodeURIComponent(val);
+  }
+
+  const parts = val.split("/");
+  let path = "";
+  for (let i = 0; i < segments.length; i++) {
+    if (segments[i] === "**" && i <= parts.length) {
+      return path + parts.slice(i).map((part) => "/" + encodeURIComponent(part)).join("");
+    }
+
+    if (i >= parts.length || parts[i] === "" || (segments[i] !== "*" && parts[i] !== segments[i])) {
+      throw new Error(`path variable "${fieldPath}": value "${val}" does not match ${segments.join("/")}`);
+    }
+    path += "/" + encodeURIComponent(parts[i]);
+  }
+
+  if (parts.length !== segments.length) {
+    throw new Error(`path variable "${fieldPath}": value "${val}" does not match ${segments.join("/")}`);
+  }
+
+  return path;
+}
+{{- end}}
+{{- if .UsesUnnamedWildcard}}
+
+function unnamedWildcard(template: string): never {
+  throw new Error(`cannot expand unnamed wildcard in ${template}`);
+}
+{{- end}}
+{{- if .UsesAppendQuery}}
+
+function appendQuery(query: URLSearchParams, key: string, value: unknown): void {
+  if (value === undefined || value === null) {
+    return;
+  }
+
+  for (const v of Array.isArray(value) ? value : [value]) {
+    query.append(key, String(v));
+  }
+}
+{{- end}}
+{{- if .UsesAppendQueryMap}}
+
+function appendQueryMap(query: URLSearchParams, key: string, value: Record<string, unknown> | undefined): void {
+  for (const [k, v] of Object.entries(value ?? {})) {
+    query.append(`${key}[${k}]`, String(v));
+  }
+}
+{{- end}}
+
+// errorInfoReason returns the reason of the google.rpc.ErrorInfo detail, which
+// carries the reason of a google.rpc.Status.
+function errorInfoReason(details: ErrorDetail[]): string | undefined {
+  const info = details.find((d) => d["@type"] === "type.googleapis.com/google.rpc.ErrorInfo");
+  return typeof info?.reason === "string" ? info.reason : undefined;
+}
+
+// codeFromHTTPStatus maps an HTTP status to the closest google.rpc.Code.
+function codeFromHTTPStatus(status: number): number {
+  switch (status) {
+    case 400:
+      return Code.INVALID_ARGUMENT;
+    case 401:
+      return Code.UNAUTHENTICATED;
+    case 403:
+      return Code.PERMISSION_DENIED;
+    case 404:
+      return Code.NOT_FOUND;
+    case 409:
+      return Code.ABORTED;
+    case 412:
+      return Code.FAILED_PRECONDITION;
+    case 416:
+      return Code.OUT_OF_RANGE;
+    case 429:
+      return Code.RESOURCE_EXHAUSTED;
+    case 499:
+      return Code.CANCELLED;
+    case 500:
+      return Code.INTERNAL;
+    case 501:
+      return Code.UNIMPLEMENTED;
+    case 503:
+      return Code.UNAVAILABLE;
+    case 504:
+      return Code.DEADLINE_EXCEEDED;
+  }
+
+  if (status >= 200 && status < 300) {
+    return Code.OK;
+  }
+  return status >= 400 && status < 500 ? Code.FAILED_PRECONDITION : Code.UNKNOWN;
+}
+{{- define "body"}}
+{{- if .RawBody}}body{{else if ne .Body ""}}JSON.stringify({{.Body}}){{else}}undefined{{end}}
+{{- end}}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/internal/plugintest"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const libraryProto = `
+name: "example/v1/library.proto"
+package: "example.v1"
+dependency: "google/api/annotations.proto"
+options { go_package: "example.com/example/v1;examplev1" }
+message_type {
+  name: "Book"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+  field { name: "page_count" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 json_name: "pageCount" }
+  field { name: "genre" number: 3 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".example.v1.Genre" json_name: "genre" }
+  field { name: "tags" number: 4 label: LABEL_REPEATED type: TYPE_STRING json_name: "tags" }
+}
+message_type {
+  name: "GetBookRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+  field { name: "genre" number: 2 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".example.v1.Genre" json_name: "genre" }
+}
+message_type {
+  name: "UpdateBookRequest"
+  field { name: "book" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".example.v1.Book" json_name: "book" }
+}
+message_type {
+  name: "UpdateBookResponse"
+  field { name: "book" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".example.v1.Book" json_name: "book" }
+}
+enum_type {
+  name: "Genre"
+  value { name: "GENRE_UNSPECIFIED" number: 0 }
+  value { name: "GENRE_FICTION" number: 1 }
+}
+service {
+  name: "Library"
+  method {
+    name: "GetBook"
+    input_type: ".example.v1.GetBookRequest"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { get: "/v1/{name=shelves/*/books/*}" } }
+  }
+  method {
+    name: "UpdateBook"
+    input_type: ".example.v1.UpdateBookRequest"
+    output_type: ".example.v1.UpdateBookResponse"
+    options { [google.api.http] { patch: "/v1/{book.name=shelves/*/books/*}" body: "book" response_body: "book" } }
+  }
+  method {
+    name: "WatchBooks"
+    input_type: ".example.v1.GetBookRequest"
+    output_type: ".example.v1.Book"
+    options { [google.api.http] { get: "/v1/{name=shelves/*}/books:watch" } }
+    server_streaming: true
+  }
+}
+syntax: "proto3"
+`
+
+// generate runs generateFile on the file described by content and returns the
+// files it generates.
+func generate(t *testing.T, content string, omitempty bool) map[string]string {
+	return plugintest.Run(t, content, func(gen *protogen.Plugin) error {
+		for _, f := range gen.Files {
+			if f.Generate {
+				generateFile(gen, f, omitempty, "")
+			}
+		}
+		return nil
+	})
+}
+
+func TestGenerateFile(t *testing.T) {
+	files := generate(t, libraryProto, true)
+
+	name := "example/v1/library_http.pb.ts"
+	content, ok := files[name]
+	if !ok || len(files) != 1 {
+		t.Fatalf("got files %v, want %s", files, name)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "source", want: "// source: example/v1/library.proto\n"},
+		{name: "int64 as string", want: "  pageCount?: string;\n"},
+		{name: "enum", want: "export type Genre =\n  | \"GENRE_UNSPECIFIED\"\n  | \"GENRE_FICTION\";\n"},
+		{name: "repeated", want: "  tags?: string[];\n"},
+		{name: "client", want: "export class LibraryHTTPClient {\n"},
+		{
+			name: "path variable and query",
+			want: "  async getBook(req: GetBookRequest, init?: RequestInit): Promise<Book> {\n" +
+				"    const query = new URLSearchParams();\n" +
+				"    appendQuery(query, \"genre\", req.genre);\n" +
+				"    const res = await send(this.options, \"GET\", \"/v1\" + expandVariable([\"shelves\", \"*\", \"books\", \"*\"], \"name\", req.name), query, undefined, init);\n",
+		},
+		{
+			name: "body and response body",
+			want: "expandVariable([\"shelves\", \"*\", \"books\", \"*\"], \"book.name\", req.book?.name), query, JSON.stringify(req.book ?? {}), init);\n" +
+				"    return { book: (await readBody(res)) as UpdateBookResponse[\"book\"] };\n",
+		},
+		{
+			name: "server streaming",
+			want: "  async *watchBooks(req: GetBookRequest, init?: RequestInit): AsyncGenerator<Book> {\n",
+		},
+		{name: "verb", want: "expandVariable([\"shelves\", \"*\"], \"name\", req.name) + \"/books:watch\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(content, tt.want) {
+				t.Fatalf("got\n%s\nwant it to contain\n%s", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateFileOmitempty(t *testing.T) {
+	content := strings.NewReplacer(
+		`options { [google.api.http] { get: "/v1/{name=shelves/*/books/*}" } }`, "",
+		`options { [google.api.http] { patch: "/v1/{book.name=shelves/*/books/*}" body: "book" response_body: "book" } }`, "",
+		`options { [google.api.http] { get: "/v1/{name=shelves/*}/books:watch" } }`, "",
+	).Replace(libraryProto)
+
+	if files := generate(t, content, true); len(files) != 0 {
+		t.Fatalf("got files %v, want none without google.api.http rules", files)
+	}
+
+	// without omitempty, the methods are routed as POST /{package.Service}/{Method}.
+	files := generate(t, content, false)
+	if got := files["example/v1/library_http.pb.ts"]; !strings.Contains(got, `"POST", "/example.v1.Library/GetBook"`) {
+		t.Fatalf("got\n%s\nwant a POST route of GetBook", got)
+	}
+}
+
+const filesProto = `
+name: "example/v1/files.proto"
+package: "example.v1"
+dependency: "google/api/annotations.proto"
+dependency: "google/api/httpbody.proto"
+options { go_package: "example.com/example/v1;examplev1" }
+message_type {
+  name: "UploadFileRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+  field { name: "file" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.api.HttpBody" json_name: "file" }
+}
+message_type {
+  name: "DownloadFileRequest"
+  field { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name" }
+}
+service {
+  name: "Files"
+  method {
+    name: "UploadFile"
+    input_type: ".example.v1.UploadFileRequest"
+    output_type: ".google.api.HttpBody"
+    options { [google.api.http] { put: "/v1/files/{name}" body: "file" } }
+  }
+  method {
+    name: "DownloadFile"
+    input_type: ".example.v1.DownloadFileRequest"
+    output_type: ".google.api.HttpBody"
+    options { [google.api.http] { get: "/v1/files/{name}:download" } }
+    server_streaming: true
+  }
+}
+syntax: "proto3"
+`
+
+func TestGenerateFileHttpBody(t *testing.T) {
+	content := generate(t, filesProto, true)["example/v1/files_http.pb.ts"]
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "raw request body", want: "    const [body, contentType] = rawBody(req.file ?? {});\n"},
+		{name: "raw response", want: "    return (await readRawBody(res)) as HttpBody;\n"},
+		{name: "raw stream", want: "    for await (const chunk of readRawStream(res)) {\n"},
+		{name: "stream accept", want: `init, "*/*");`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(content, tt.want) {
+				t.Fatalf("got\n%s\nwant it to contain\n%s", content, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package httproute
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// QueryField is a request field bound from the query string.
+type QueryField struct {
+	// Name is the query key of the field, the proto names of Fields joined
+	// by ".", such as filter.query. Map entries are keyed as name[key].
+	Name   string
+	Fields []*protogen.Field // from the request message down to the field
+}
+
+// Field returns the field bound by the query key.
+func (f *QueryField) Field() *protogen.Field {
+	return f.Fields[len(f.Fields)-1]
+}
+
+// QueryFields returns the fields of the request that the gohttp binder reads
+// from the query string: the ones not bound by the path or the body, nested
+// messages being walked once per path to stop at recursive messages. Repeated
+// messages and the well-known types without a string form are left out.
+func (r *Route) QueryFields() []*QueryField {
+	if r.Body == BodyWildcard {
+		return nil
+	}
+
+	bound := r.Template.FieldPaths()
+	if r.Body != "" {
+		bound = append(bound, r.Body)
+	}
+
+	in := r.Method.Input
+	return queryFields(in, nil, bound, map[*protogen.Message]bool{in: true})
+}
+
+func queryFields(msg *protogen.Message, parents []*protogen.Field, bound []string, visiting map[*protogen.Message]bool) []*QueryField {
+	var fields []*QueryField
+	for _, field := range msg.Fields {
+		path := append(parents[:len(parents):len(parents)], field)
+		name := fieldPathName(path)
+		if isBoundField(name, bound) {
+			continue
+		}
+
+		switch {
+		case field.Desc.IsMap():
+		case field.Message != nil && isWellKnown(field.Message.Desc.FullName()):
+			if !hasStringForm(field.Message.Desc.FullName()) {
+				continue
+			}
+		case field.Message != nil:
+			if field.Desc.IsList() || visiting[field.Message] {
+				continue
+			}
+
+			visiting[field.Message] = true
+			fields = append(fields, queryFields(field.Message, path, bound, visiting)...)
+			delete(visiting, field.Message)
+			continue
+		}
+
+		fields = append(fields, &QueryField{Name: name, Fields: path})
+	}
+
+	return fields
+}
+
+func fieldPathName(fields []*protogen.Field) string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, string(field.Desc.Name()))
+	}
+
+	return strings.Join(names, ".")
+}
+
+func isBoundField(fieldPath string, bound []string) bool {
+	for _, b := range bound {
+		if fieldPath == b || strings.HasPrefix(fieldPath, b+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWellKnown(name protoreflect.FullName) bool {
+	return name.Parent() == "google.protobuf"
+}
+
+// hasStringForm reports whether the well-known type is parsed by the binder
+// from a single query value.
+func hasStringForm(name protoreflect.FullName) bool {
+	switch name {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return true
+	default:
+		return false
+	}
+}
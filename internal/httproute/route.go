@@ -0,0 +1,208 @@
+// Package httproute resolves the HTTP routes of protobuf services from their
+// google.api.http annotations. It is shared by the protoc plugins, so that
+// every generated artifact agrees with the routes the gohttp server registers.
+package httproute
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+)
+
+const BodyWildcard = "*"
+
+type Options struct {
+	// Omitempty skips the methods without a google.api.http rule, which are
+	// otherwise routed as POST {OmitemptyPrefix}/{package.Service}/{Method}.
+	Omitempty       bool
+	OmitemptyPrefix string
+	// WebSocket routes the client and bidi streaming methods.
+	WebSocket bool
+}
+
+// Route is a single binding of a method to an HTTP method and path.
+type Route struct {
+	Method       *protogen.Method
+	HTTPMethod   string
+	Path         string
+	Template     *httprule.Template
+	Body         string
+	ResponseBody string
+}
+
+// ServiceRoutes returns the routes of the methods of service, the additional
+// bindings of a method coming before its main rule. Invalid rules are reported
+// with gen.Error and left out.
+func ServiceRoutes(gen *protogen.Plugin, service *protogen.Service, opts Options) []*Route {
+	var routes []*Route
+	for _, method := range service.Methods {
+		if method.Desc.IsStreamingClient() && !opts.WebSocket {
+			continue
+		}
+
+		rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if rule != nil && ok {
+			for _, bind := range rule.AdditionalBindings {
+				routes = appendRoute(routes, buildRoute(gen, service, method, bind, opts.OmitemptyPrefix))
+			}
+
+			routes = appendRoute(routes, buildRoute(gen, service, method, rule, opts.OmitemptyPrefix))
+			continue
+		}
+
+		if !opts.Omitempty {
+			routes = appendRoute(routes, buildRoute(gen, service, method, &annotations.HttpRule{Body: BodyWildcard}, opts.OmitemptyPrefix))
+		}
+	}
+
+	return routes
+}
+
+// HasHTTPRule reports whether a method of services has a google.api.http rule.
+func HasHTTPRule(services []*protogen.Service, websocket bool) bool {
+	for _, service := range services {
+		for _, method := range service.Methods {
+			if method.Desc.IsStreamingClient() && !websocket {
+				continue
+			}
+
+			rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if rule != nil && ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func appendRoute(routes []*Route, route *Route) []*Route {
+	if route == nil {
+		return routes
+	}
+
+	return append(routes, route)
+}
+
+// buildRoute returns the route of a rule, or nil when the rule is invalid.
+func buildRoute(gen *protogen.Plugin, service *protogen.Service, m *protogen.Method, rule *annotations.HttpRule, omitemptyPrefix string) *Route {
+	var (
+		path   string
+		method string
+		valid  = true
+	)
+
+	report := func(err error) {
+		gen.Error(err)
+		valid = false
+	}
+
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		path = pattern.Get
+		method = http.MethodGet
+	case *annotations.HttpRule_Put:
+		path = pattern.Put
+		method = http.MethodPut
+	case *annotations.HttpRule_Post:
+		path = pattern.Post
+		method = http.MethodPost
+	case *annotations.HttpRule_Delete:
+		path = pattern.Delete
+		method = http.MethodDelete
+	case *annotations.HttpRule_Patch:
+		path = pattern.Patch
+		method = http.MethodPatch
+	case *annotations.HttpRule_Custom:
+		path = pattern.Custom.Path
+		// chi matches methods by their upper case name.
+		method = strings.ToUpper(pattern.Custom.Kind)
+		if method == "" || strings.ContainsAny(method, " \t\r\n/(){}[]<>@,;:\\\"?=") {
+			report(fmt.Errorf("%s: invalid custom method kind %q", m.Desc.FullName(), pattern.Custom.Kind))
+		}
+	default:
+		path = fmt.Sprintf("%s/%s/%s", omitemptyPrefix, service.Desc.FullName(), m.Desc.Name())
+		method = http.MethodPost
+	}
+
+	tmpl, err := httprule.Parse(path)
+	if err != nil {
+		report(fmt.Errorf("%s: %w", m.Desc.FullName(), err))
+	} else if m.Desc.IsStreamingClient() && len(tmpl.Variables) != 0 {
+		// a websocket is dialed before the first request message is sent.
+		report(fmt.Errorf("%s: client streaming methods cannot bind path variables", m.Desc.FullName()))
+	} else {
+		for _, fieldPath := range tmpl.FieldPaths() {
+			if err := checkPathField(m.Input, fieldPath); err != nil {
+				report(fmt.Errorf("%s: path variable %q: %w", m.Desc.FullName(), fieldPath, err))
+			}
+		}
+	}
+
+	if rule.Body != "" && rule.Body != BodyWildcard && FindField(m.Input, rule.Body) == nil {
+		report(fmt.Errorf("%s: body field %q not found in %s", m.Desc.FullName(), rule.Body, m.Input.Desc.FullName()))
+	}
+
+	if rule.ResponseBody != "" && FindField(m.Output, rule.ResponseBody) == nil {
+		report(fmt.Errorf("%s: response_body field %q not found in %s", m.Desc.FullName(), rule.ResponseBody, m.Output.Desc.FullName()))
+	}
+
+	if !valid {
+		return nil
+	}
+
+	return &Route{
+		Method:       m,
+		HTTPMethod:   method,
+		Path:         path,
+		Template:     tmpl,
+		Body:         rule.Body,
+		ResponseBody: rule.ResponseBody,
+	}
+}
+
+// FindField returns the top-level field of msg with the given proto name.
+func FindField(msg *protogen.Message, name string) *protogen.Field {
+	for _, field := range msg.Fields {
+		if string(field.Desc.Name()) == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// checkPathField checks that a dotted path variable resolves to a singular
+// scalar field through singular message fields.
+func checkPathField(msg *protogen.Message, fieldPath string) error {
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		field := FindField(msg, name)
+		if field == nil {
+			return fmt.Errorf("field %q not found in %s", name, msg.Desc.FullName())
+		}
+
+		if field.Desc.IsList() || field.Desc.IsMap() {
+			return fmt.Errorf("field %q is repeated", name)
+		}
+
+		if i == len(names)-1 {
+			if field.Message != nil {
+				return fmt.Errorf("field %q is a message", name)
+			}
+			return nil
+		}
+
+		if field.Message == nil {
+			return fmt.Errorf("field %q is not a message", name)
+		}
+		msg = field.Message
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package plugintest runs the protoc plugins of this repository on files
+// described in the protobuf text format, so that their output can be checked
+// without invoking protoc.
+package plugintest
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	_ "google.golang.org/genproto/googleapis/api/httpbody"
+)
+
+// Run generates the file described by the text format FileDescriptorProto
+// file with fn and returns the content of the generated files by name. Its dependencies are resolved from the linked in files, such
+// as google/api/annotations.proto.
+func Run(t *testing.T, file string, fn func(gen *protogen.Plugin) error) map[string]string {
+	t.Helper()
+
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(file), fd); err != nil {
+		t.Fatalf("parse %s: %v", file, err)
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.GetName()},
+	}
+	seen := make(map[string]bool)
+	var addDeps func(names []string)
+	addDeps = func(names []string) {
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			dep, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				t.Fatalf("dependency %s: %v", name, err)
+			}
+			dfd := protodesc.ToFileDescriptorProto(dep)
+			addDeps(dfd.GetDependency())
+			req.ProtoFile = append(req.ProtoFile, dfd)
+		}
+	}
+	addDeps(fd.GetDependency())
+	req.ProtoFile = append(req.ProtoFile, fd)
+
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fn(gen); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := gen.Response()
+	if resp.Error != nil {
+		t.Fatalf("generate %s: %s", fd.GetName(), resp.GetError())
+	}
+
+	files := make(map[string]string)
+	for _, f := range resp.File {
+		files[f.GetName()] = f.GetContent()
+	}
+
+	return files
+}
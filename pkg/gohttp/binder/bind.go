@@ -1,21 +1,40 @@
 package binder
 
+import (
+	"fmt"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+)
+
 func (d *RequestDecoder) Bind(v interface{}) error {
+	if d.Opts == nil {
+		d.Opts = option.NewBinderOptions()
+	}
 	hasBody := hasBody(d.Request)
 
 	d.BindHeader()
-	if err := d.BindParams(v); err != nil {
-		return err
+
+	if hasBody {
+		if d.Opts.Body == "" {
+			return fmt.Errorf("request body is not allowed, %w", errors.ErrGeneralBadRequest)
+		}
+		if err := d.BindBody(v); err != nil {
+			return err
+		}
 	}
-	if err := d.BindQuery(v); err != nil {
+
+	if err := d.BindParams(v); err != nil {
 		return err
 	}
 
-	if !hasBody {
+	// google.api.http: when the body maps the whole request there are no
+	// fields left to be populated from the query string.
+	if d.Opts.Body == bodyWildcard {
 		return nil
 	}
 
-	if err := d.BindBody(v); err != nil {
+	if err := d.BindQuery(v); err != nil {
 		return err
 	}
 
@@ -23,17 +42,18 @@ func (d *RequestDecoder) Bind(v interface{}) error {
 }
 
 func (e *RequestEncoder) Bind(v interface{}) error {
-	shouldHaveBody := shouldHaveBody(e.Request.Method)
-
 	e.BindHeader()
 	if err := e.BindParams(v); err != nil {
 		return err
 	}
-	if !shouldHaveBody {
+
+	if e.Opts.Body != bodyWildcard {
 		if err := e.BindQuery(v); err != nil {
 			return err
 		}
-	} else {
+	}
+
+	if e.Opts.Body != "" {
 		if err := e.BindBody(v); err != nil {
 			return err
 		}
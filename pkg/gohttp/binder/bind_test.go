@@ -0,0 +1,124 @@
+package binder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+)
+
+func TestRequestDecoderBindBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		content     string
+		contentType string
+		want        *apipb.Api
+		wantErr     error
+	}{
+		{
+			name:    "whole request",
+			body:    "*",
+			content: `{"name":"a","version":"v1","sourceContext":{"fileName":"a.proto"}}`,
+			want:    &apipb.Api{Name: "a", Version: "v1", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}},
+		},
+		{
+			name:    "message field",
+			body:    "source_context",
+			content: `{"fileName":"a.proto"}`,
+			want:    &apipb.Api{SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}},
+		},
+		{
+			name:    "repeated field",
+			body:    "mixins",
+			content: `[{"name":"a"},{"name":"b"}]`,
+			want:    &apipb.Api{Mixins: []*apipb.Mixin{{Name: "a"}, {Name: "b"}}},
+		},
+		{
+			name: "no body",
+			want: &apipb.Api{},
+		},
+		{
+			name:    "body not allowed",
+			content: `{"name":"a"}`,
+			wantErr: potErrors.ErrGeneralBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			body:        "*",
+			content:     `name: "a"`,
+			contentType: "text/plain",
+			wantErr:     potErrors.ErrGeneralUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/apis", strings.NewReader(tt.content))
+			if tt.content != "" {
+				contentType := tt.contentType
+				if contentType == "" {
+					contentType = option.ContentTypeApplicationJson.String()
+				}
+				req.Header.Set("Content-Type", contentType)
+			}
+
+			got := new(apipb.Api)
+			err := NewRequestDecoder(req, option.WithBody(tt.body)).Bind(got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestEncoderBindBody(t *testing.T) {
+	in := &apipb.Api{Name: "a", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}}
+
+	tests := []struct {
+		name string
+		body string
+		want proto.Message
+	}{
+		{name: "whole request", body: "*", want: in},
+		{name: "message field", body: "source_context", want: in.SourceContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/apis", nil)
+			if err := NewRequestEncoder(req, option.WithBody(tt.body)).BindBody(in); err != nil {
+				t.Fatal(err)
+			}
+
+			// the decoder of the same rule reads back the part the encoder wrote.
+			req.Header.Set("Content-Type", option.ContentTypeApplicationJson.String())
+			got := new(apipb.Api)
+			if err := NewRequestDecoder(req, option.WithBody(tt.body)).BindBody(got); err != nil {
+				t.Fatal(err)
+			}
+
+			var part proto.Message = got
+			if tt.body != "*" {
+				part = got.SourceContext
+			}
+			if !proto.Equal(part, tt.want) {
+				t.Fatalf("got %v, want %v", part, tt.want)
+			}
+		})
+	}
+}
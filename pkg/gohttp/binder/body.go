@@ -2,86 +2,263 @@ package binder
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/codec"
 	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
 	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
-	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func (d *RequestDecoder) BindBody(v interface{}) error {
 	// check content type of request
-	switch d.Request.Header.Get("Content-Type") {
-	case option.ContentTypeApplicationJson.String():
+	contentType := d.Request.Header.Get("Content-Type")
+
+	// google.api.HttpBody holds the raw request body, whatever its type.
+	if hb, ok := httpBody(v, d.Opts.Body, true); ok {
 		body, err := io.ReadAll(d.Request.Body)
 		if err != nil {
 			return err
 		}
 
-		return protojson.Unmarshal(body, v.(protoreflect.ProtoMessage))
-	case "":
+		setHttpBody(hb, contentType, body)
+		return nil
+	}
+
+	if contentType == "" {
 		return nil
-	default:
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(contentType); isForm(mediaType) {
+		return d.bindForm(v, contentType)
+	}
+
+	c, ok := codecFor(d.Opts, contentType)
+	if !ok {
 		return fmt.Errorf("content-type is not supported, %w", errors.ErrGeneralUnsupportedMediaType)
 	}
+
+	body, err := io.ReadAll(d.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := unmarshalBody(c, body, v.(protoreflect.ProtoMessage), d.Opts.Body); err != nil {
+		return fmt.Errorf("%v, %w", err, errors.ErrGeneralBadRequest)
+	}
+
+	return nil
 }
 
 func (d *ResponseDecoder) BindBody(v interface{}) error {
 	// check content type of request
-	switch d.Response.Header.Get("Content-Type") {
-	case option.ContentTypeApplicationJson.String():
+	contentType := d.Response.Header.Get("Content-Type")
+
+	if hb, ok := httpBody(v, responseBodyField(d.Opts), true); ok {
 		body, err := io.ReadAll(d.Response.Body)
 		if err != nil {
 			return err
 		}
 
-		if protoMessage, ok := v.(protoreflect.ProtoMessage); ok {
-			return protojson.Unmarshal(body, protoMessage)
-		} else {
-			return json.Unmarshal(body, v)
-		}
-	case "":
+		setHttpBody(hb, contentType, body)
 		return nil
-	default:
+	}
+
+	if contentType == "" {
+		return nil
+	}
+
+	c, ok := codecFor(d.Opts, contentType)
+	if !ok {
 		return fmt.Errorf("content-type is not supported, %w", errors.ErrGeneralUnsupportedMediaType)
 	}
+
+	body, err := io.ReadAll(d.Response.Body)
+	if err != nil {
+		return err
+	}
+
+	// responses to HEAD requests carry the headers without the body.
+	if len(body) == 0 {
+		return nil
+	}
+
+	if protoMessage, ok := v.(protoreflect.ProtoMessage); ok {
+		return unmarshalBody(c, body, protoMessage, responseBodyField(d.Opts))
+	}
+
+	return c.Unmarshal(body, v)
 }
 
 func (e *RequestEncoder) BindBody(v interface{}) error {
 	var content []byte
-	switch e.Opts.ContentType {
-	case option.ContentTypeApplicationJson:
+	if hb, ok := httpBody(v, e.Opts.Body, false); ok {
+		// google.api.HttpBody is sent as the raw body with its own content type.
+		var contentType string
+		contentType, content = httpBodyContent(hb)
+		e.Request.Header.Set("Content-Type", contentType)
+	} else {
+		c, ok := codecFor(e.Opts, e.Opts.ContentType.String())
+		if !ok {
+			return fmt.Errorf("content-type is not supported, %w", errors.ErrGeneralUnsupportedMediaType)
+		}
+
 		var err error
-		content, err = protojson.Marshal(v.(protoreflect.ProtoMessage))
-		if err != nil {
+		if content, err = marshalBody(c, v.(protoreflect.ProtoMessage), e.Opts.Body); err != nil {
 			return err
 		}
-	default:
-		return fmt.Errorf("content-type is not supported, %w", errors.ErrGeneralUnsupportedMediaType)
 	}
 
-	e.Request.Body = io.NopCloser(bytes.NewBuffer(content))
+	// GetBody rebuilds the body when the request is retried or redirected.
+	e.Request.ContentLength = int64(len(content))
+	e.Request.Body = io.NopCloser(bytes.NewReader(content))
+	e.Request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
 	return nil
 }
 
+// BindBody writes v with the codec of the content type of the options, or as
+// JSON when no codec is registered for it. A google.api.HttpBody is written as
+// the raw body with its own content type.
 func (e *ResponseEncoder) BindBody(v interface{}) error {
+	if hb, ok := httpBody(v, responseBodyField(e.Opts), false); ok {
+		contentType, content := httpBodyContent(hb)
+		e.ResponseWriter.Header().Set("Content-Type", contentType)
+		_, err := e.ResponseWriter.Write(content)
+		return err
+	}
+
+	c, ok := codecFor(e.Opts, e.Opts.ContentType.String())
+	if !ok {
+		c = jsonCodec(e.Opts)
+	}
+
 	var content []byte
 	var err error
-
 	if protoMessage, ok := v.(protoreflect.ProtoMessage); ok {
-		content, err = protojson.Marshal(protoMessage)
+		content, err = marshalBody(c, protoMessage, responseBodyField(e.Opts))
 	} else {
-		content, err = json.Marshal(v)
+		content, err = c.Marshal(v)
 	}
 
 	if err != nil {
 		return err
 	}
 
-	e.ResponseWriter.Header().Set("Content-Type", option.ContentTypeApplicationJson.String())
+	e.ResponseWriter.Header().Set("Content-Type", c.ContentType())
 	_, err = e.ResponseWriter.Write(content)
 	return err
 }
+
+// unmarshalBody decodes body with c into the part of m selected by the
+// google.api.http body field, "*" being the whole message.
+func unmarshalBody(c codec.Codec, body []byte, m protoreflect.ProtoMessage, field string) error {
+	if field == bodyWildcard {
+		return c.Unmarshal(body, m)
+	}
+
+	msg := m.ProtoReflect()
+	fd, err := bodyField(msg, field)
+	if err != nil {
+		return err
+	}
+
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+		return c.Unmarshal(body, msg.Mutable(fd).Message().Interface())
+	}
+
+	if fc, ok := c.(codec.FieldCodec); ok {
+		return fc.UnmarshalField(body, msg, fd)
+	}
+
+	// the field is encoded as the message holding just that field.
+	tmp := msg.New()
+	if err := c.Unmarshal(body, tmp.Interface()); err != nil {
+		return err
+	}
+	if tmp.Has(fd) {
+		msg.Set(fd, tmp.Get(fd))
+	}
+
+	return nil
+}
+
+// marshalBody encodes with c the part of m selected by the google.api.http
+// body field, "*" being the whole message.
+func marshalBody(c codec.Codec, m protoreflect.ProtoMessage, field string) ([]byte, error) {
+	if field == bodyWildcard {
+		return c.Marshal(m)
+	}
+
+	msg := m.ProtoReflect()
+	fd, err := bodyField(msg, field)
+	if err != nil {
+		return nil, err
+	}
+
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+		return c.Marshal(msg.Get(fd).Message().Interface())
+	}
+
+	if fc, ok := c.(codec.FieldCodec); ok {
+		return fc.MarshalField(msg, fd)
+	}
+
+	tmp := msg.New()
+	if msg.Has(fd) {
+		tmp.Set(fd, msg.Get(fd))
+	}
+
+	return c.Marshal(tmp.Interface())
+}
+
+// codecFor returns the codec registered for contentType, the JSON codec using
+// the protojson options set by opts.
+func codecFor(opts *option.BinderOptions, contentType string) (codec.Codec, bool) {
+	c, ok := codec.ForContentType(contentType)
+	if !ok || opts == nil {
+		return c, ok
+	}
+
+	if j, isJSON := c.(codec.JSON); isJSON {
+		if opts.JSONMarshalOptions != nil {
+			j.MarshalOptions = *opts.JSONMarshalOptions
+		}
+		if opts.JSONUnmarshalOptions != nil {
+			j.UnmarshalOptions = *opts.JSONUnmarshalOptions
+		}
+		return j, true
+	}
+
+	return c, true
+}
+
+// jsonCodec returns the JSON codec for opts, which encodes the messages of
+// streams.
+func jsonCodec(opts *option.BinderOptions) codec.Codec {
+	c, _ := codecFor(opts, option.ContentTypeApplicationJson.String())
+	return c
+}
+
+// responseBodyField returns the google.api.http response_body field, the whole
+// reply being serialized when it is unset.
+func responseBodyField(opts *option.BinderOptions) string {
+	if opts == nil || opts.ResponseBody == "" {
+		return bodyWildcard
+	}
+
+	return opts.ResponseBody
+}
+
+// bodyField looks up the top-level field named by a google.api.http body.
+func bodyField(msg protoreflect.Message, field string) (protoreflect.FieldDescriptor, error) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(field))
+	if fd == nil {
+		return nil, fmt.Errorf("body field %q not found in %s", field, msg.Descriptor().FullName())
+	}
+
+	return fd, nil
+}
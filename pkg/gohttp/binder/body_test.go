@@ -0,0 +1,230 @@
+package binder
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/codec"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+)
+
+func TestResponseBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		in           *apipb.Api
+		wantContent  string
+		want         *apipb.Api
+	}{
+		{
+			name:        "whole reply",
+			in:          &apipb.Api{Name: "a", Version: "v1"},
+			wantContent: `{"name":"a","version":"v1"}`,
+			want:        &apipb.Api{Name: "a", Version: "v1"},
+		},
+		{
+			name:         "message field",
+			responseBody: "source_context",
+			in:           &apipb.Api{Name: "a", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}},
+			wantContent:  `{"fileName":"a.proto"}`,
+			want:         &apipb.Api{SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}},
+		},
+		{
+			name:         "repeated field",
+			responseBody: "mixins",
+			in:           &apipb.Api{Name: "a", Mixins: []*apipb.Mixin{{Name: "m"}}},
+			wantContent:  `[{"name":"m"}]`,
+			want:         &apipb.Api{Mixins: []*apipb.Mixin{{Name: "m"}}},
+		},
+		{
+			name:         "unpopulated scalar field",
+			responseBody: "version",
+			in:           &apipb.Api{Name: "a"},
+			wantContent:  `""`,
+			want:         &apipb.Api{},
+		},
+		{
+			name:         "unpopulated repeated field",
+			responseBody: "mixins",
+			in:           &apipb.Api{Name: "a"},
+			wantContent:  `[]`,
+			want:         &apipb.Api{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := NewResponseEncoder(rec, option.WithResponseBody(tt.responseBody)).BindBody(tt.in); err != nil {
+				t.Fatal(err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			json.Unmarshal([]byte(tt.wantContent), &want)
+			if gotJSON, wantJSON := mustJSON(t, got), mustJSON(t, want); gotJSON != wantJSON {
+				t.Fatalf("got body %s, want %s", gotJSON, wantJSON)
+			}
+
+			// the client decodes the payload back into the response_body field.
+			out := new(apipb.Api)
+			if err := NewResponseDecoder(rec.Result(), option.WithResponseBody(tt.responseBody)).BindBody(out); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(out, tt.want) {
+				t.Fatalf("got %v, want %v", out, tt.want)
+			}
+		})
+	}
+}
+
+// mustJSON re-encodes a decoded JSON value, its object keys sorted.
+func mustJSON(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestProtoBody(t *testing.T) {
+	in := &apipb.Api{Name: "a", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}, Mixins: []*apipb.Mixin{{Name: "m"}}}
+
+	tests := []struct {
+		name  string
+		field string
+		want  *apipb.Api
+	}{
+		{name: "whole message", field: "*", want: in},
+		{name: "message field", field: "source_context", want: &apipb.Api{SourceContext: in.SourceContext}},
+		{name: "repeated field", field: "mixins", want: &apipb.Api{Mixins: in.Mixins}},
+	}
+	for _, tt := range tests {
+		for _, contentType := range []option.ContentType{option.ContentTypeProtobuf, option.ContentTypeProto} {
+			t.Run(tt.name+" "+contentType.String(), func(t *testing.T) {
+				// request body, from the client to the server.
+				req := httptest.NewRequest(http.MethodPost, "/v1/apis", nil)
+				enc := NewRequestEncoder(req, option.WithBody(tt.field), option.WithContentType(contentType))
+				enc.BindHeader()
+				if err := enc.BindBody(in); err != nil {
+					t.Fatal(err)
+				}
+				if got := req.Header.Get("Content-Type"); got != contentType.String() {
+					t.Fatalf("got request content type %s, want %s", got, contentType)
+				}
+
+				got := new(apipb.Api)
+				if err := NewRequestDecoder(req, option.WithBody(tt.field)).BindBody(got); err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(got, tt.want) {
+					t.Fatalf("got request %v, want %v", got, tt.want)
+				}
+
+				// response body, from the server to the client.
+				rec := httptest.NewRecorder()
+				if err := NewResponseEncoder(rec, option.WithResponseBody(tt.field), option.WithContentType(contentType)).BindBody(in); err != nil {
+					t.Fatal(err)
+				}
+				if got := rec.Header().Get("Content-Type"); got != contentType.String() {
+					t.Fatalf("got response content type %s, want %s", got, contentType)
+				}
+
+				got = new(apipb.Api)
+				if err := NewResponseDecoder(rec.Result(), option.WithResponseBody(tt.field)).BindBody(got); err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(got, tt.want) {
+					t.Fatalf("got response %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestJSONOptions(t *testing.T) {
+	in := &apipb.Api{Name: "a", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}}
+
+	tests := []struct {
+		name     string
+		register *codec.JSON
+		opts     []option.BinderOption
+		want     string
+	}{
+		{name: "default", want: `{"name":"a","sourceContext":{"fileName":"a.proto"}}`},
+		{
+			name:     "registered codec",
+			register: &codec.JSON{MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}},
+			want:     `{"name":"a","source_context":{"file_name":"a.proto"}}`,
+		},
+		{
+			name: "call options",
+			opts: []option.BinderOption{option.WithJSONMarshalOptions(protojson.MarshalOptions{UseProtoNames: true})},
+			want: `{"name":"a","source_context":{"file_name":"a.proto"}}`,
+		},
+		{
+			name:     "call options over the registered codec",
+			register: &codec.JSON{MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}},
+			opts:     []option.BinderOption{option.WithJSONMarshalOptions(protojson.MarshalOptions{})},
+			want:     `{"name":"a","sourceContext":{"fileName":"a.proto"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.register != nil {
+				codec.Register(*tt.register)
+				defer codec.Register(codec.JSON{})
+			}
+
+			rec := httptest.NewRecorder()
+			if err := NewResponseEncoder(rec, tt.opts...).BindBody(in); err != nil {
+				t.Fatal(err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			json.Unmarshal([]byte(tt.want), &want)
+			if gotJSON, wantJSON := mustJSON(t, got), mustJSON(t, want); gotJSON != wantJSON {
+				t.Fatalf("got body %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestJSONUnmarshalOptions(t *testing.T) {
+	content := `{"name":"a","unknown":1}`
+
+	for _, tt := range []struct {
+		name    string
+		opts    []option.BinderOption
+		wantErr bool
+	}{
+		{name: "default", wantErr: true},
+		{name: "discard unknown", opts: []option.BinderOption{option.WithJSONUnmarshalOptions(protojson.UnmarshalOptions{DiscardUnknown: true})}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/apis", strings.NewReader(content))
+			req.Header.Set("Content-Type", option.ContentTypeApplicationJson.String())
+
+			got := new(apipb.Api)
+			err := NewRequestDecoder(req, append([]option.BinderOption{option.WithBody("*")}, tt.opts...)...).BindBody(got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.GetName() != "a" {
+				t.Fatalf("got %v, want the name", got)
+			}
+		})
+	}
+}
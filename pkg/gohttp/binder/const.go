@@ -1,10 +1,33 @@
 package binder
 
+import "google.golang.org/protobuf/reflect/protoreflect"
+
 const (
 	contextHeaderPrefix = "header__"
 
-	structTag = "query"
+	bodyWildcard       = "*"
+	fieldPathDelimiter = "."
+
+	streamErrorEvent = "error"
+
+	// rawStreamChunkSize bounds the chunks of a raw body stream received as
+	// google.api.HttpBody messages.
+	rawStreamChunkSize = 32 * 1024
+)
+
+const (
+	timestampName   protoreflect.FullName = "google.protobuf.Timestamp"
+	durationName    protoreflect.FullName = "google.protobuf.Duration"
+	fieldMaskName   protoreflect.FullName = "google.protobuf.FieldMask"
+	doubleValueName protoreflect.FullName = "google.protobuf.DoubleValue"
+	floatValueName  protoreflect.FullName = "google.protobuf.FloatValue"
+	int64ValueName  protoreflect.FullName = "google.protobuf.Int64Value"
+	uint64ValueName protoreflect.FullName = "google.protobuf.UInt64Value"
+	int32ValueName  protoreflect.FullName = "google.protobuf.Int32Value"
+	uint32ValueName protoreflect.FullName = "google.protobuf.UInt32Value"
+	boolValueName   protoreflect.FullName = "google.protobuf.BoolValue"
+	stringValueName protoreflect.FullName = "google.protobuf.StringValue"
+	bytesValueName  protoreflect.FullName = "google.protobuf.BytesValue"
 
-	structTagConfigDelimiter       = ","
-	structTagDefaultValueDelimiter = ","
+	httpBodyName protoreflect.FullName = "google.api.HttpBody"
 )
@@ -1,11 +1,31 @@
 package binder
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+)
 
 type RequestDecoder struct {
+	Opts    *option.BinderOptions
 	Request *http.Request
 }
 
 type ResponseDecoder struct {
+	Opts     *option.BinderOptions
 	Response *http.Response
 }
+
+func NewRequestDecoder(r *http.Request, opts ...option.BinderOption) *RequestDecoder {
+	return &RequestDecoder{
+		Opts:    option.NewBinderOptions(opts...),
+		Request: r,
+	}
+}
+
+func NewResponseDecoder(r *http.Response, opts ...option.BinderOption) *ResponseDecoder {
+	return &ResponseDecoder{
+		Opts:     option.NewBinderOptions(opts...),
+		Response: r,
+	}
+}
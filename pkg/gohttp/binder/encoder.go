@@ -12,6 +12,7 @@ type RequestEncoder struct {
 }
 
 type ResponseEncoder struct {
+	Opts           *option.BinderOptions
 	ResponseWriter http.ResponseWriter
 }
 
@@ -21,3 +22,10 @@ func NewRequestEncoder(r *http.Request, opts ...option.BinderOption) *RequestEnc
 		Request: r,
 	}
 }
+
+func NewResponseEncoder(w http.ResponseWriter, opts ...option.BinderOption) *ResponseEncoder {
+	return &ResponseEncoder{
+		Opts:           option.NewBinderOptions(opts...),
+		ResponseWriter: w,
+	}
+}
@@ -0,0 +1,75 @@
+package binder
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// BindError writes the body reporting an error with the given status code.
+func (e *ResponseEncoder) BindError(statusCode int, v interface{}) error {
+	content, err := marshalError(v)
+	if err != nil {
+		return err
+	}
+
+	e.ResponseWriter.Header().Set("Content-Type", option.ContentTypeApplicationJson.String())
+	e.ResponseWriter.WriteHeader(statusCode)
+	_, err = e.ResponseWriter.Write(content)
+	return err
+}
+
+// BindError decodes the error reported by the response, a google.rpc.Status
+// or an errors.Error body. Responses without a JSON body, such as the ones of
+// a proxy, are reported by the status code alone.
+func (d *ResponseDecoder) BindError() error {
+	body, err := io.ReadAll(d.Response.Body)
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(d.Response.Header.Get("Content-Type"))
+	if len(body) == 0 || option.ContentType(mediaType) != option.ContentTypeApplicationJson {
+		if err := errors.ErrorMap[d.Response.StatusCode]; err != nil {
+			return err
+		}
+		return errors.NewCode(errors.CodeFromHTTPStatus(d.Response.StatusCode), http.StatusText(d.Response.StatusCode))
+	}
+
+	err = decodeError(body)
+
+	// an errors.Error body does not carry its code, rebuild it from the status.
+	customErr := &errors.Error{}
+	if stderrors.As(err, &customErr) && customErr.Code == codes.OK {
+		customErr.Code = errors.CodeFromHTTPStatus(d.Response.StatusCode)
+	}
+
+	return err
+}
+
+func marshalError(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Marshal(m)
+	}
+
+	return json.Marshal(v)
+}
+
+// decodeError decodes a google.rpc.Status or an errors.Error body, both
+// sharing the code, message and details fields.
+func decodeError(data []byte) error {
+	customErr := new(errors.Error)
+	if err := json.Unmarshal(data, customErr); err != nil {
+		return err
+	}
+
+	return errors.Resolve(customErr)
+}
@@ -0,0 +1,98 @@
+package binder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestResponseDecoderBindError(t *testing.T) {
+	detail, err := anypb.New(wrapperspb.String("detail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		statusCode  int
+		body        interface{}
+		raw         string
+		contentType string
+		wantCode    codes.Code
+		wantMessage string
+		wantDetails []*anypb.Any
+	}{
+		{
+			name:        "status",
+			statusCode:  http.StatusNotFound,
+			body:        &status.Status{Code: 5, Message: "no such book", Details: []*anypb.Any{detail}},
+			wantCode:    codes.NotFound,
+			wantMessage: "no such book",
+			wantDetails: []*anypb.Any{detail},
+		},
+		{
+			name:        "status with an unknown detail",
+			statusCode:  http.StatusNotFound,
+			raw:         `{"code":5,"message":"no such book","details":[{"@type":"type.googleapis.com/test.Unknown"}]}`,
+			contentType: "application/json",
+			wantCode:    codes.NotFound,
+			wantMessage: "no such book",
+		},
+		{
+			name:        "error",
+			statusCode:  http.StatusBadRequest,
+			body:        potErrors.New("invalid book"),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "invalid book",
+		},
+		{
+			name:        "no body",
+			statusCode:  http.StatusBadGateway,
+			raw:         "bad gateway",
+			contentType: "text/plain",
+			wantMessage: potErrors.ErrGeneralBadGateway.Message,
+		},
+		{
+			name:        "no body with an unmapped status",
+			statusCode:  499,
+			contentType: "text/plain",
+			wantCode:    codes.Canceled,
+			wantMessage: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if tt.body != nil {
+				if err := NewResponseEncoder(rec).BindError(tt.statusCode, tt.body); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				rec.Header().Set("Content-Type", tt.contentType)
+				rec.WriteHeader(tt.statusCode)
+				rec.WriteString(tt.raw)
+			}
+			if rec.Code != tt.statusCode {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.statusCode)
+			}
+
+			err := NewResponseDecoder(rec.Result()).BindError()
+			potErr, ok := err.(*potErrors.Error)
+			if !ok || potErr.Code != tt.wantCode || potErr.Message != tt.wantMessage || len(potErr.Details) != len(tt.wantDetails) {
+				t.Fatalf("got %#v, want code %v and message %q", err, tt.wantCode, tt.wantMessage)
+			}
+			for i, d := range potErr.Details {
+				if !proto.Equal(d, tt.wantDetails[i]) {
+					t.Fatalf("got detail %v, want %v", d, tt.wantDetails[i])
+				}
+			}
+		})
+	}
+}
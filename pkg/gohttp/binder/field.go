@@ -0,0 +1,303 @@
+package binder
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errFieldNotFound = errors.New("field not found")
+
+// resolveFieldPath resolves a dotted field path such as "user.id" against a
+// message descriptor, accepting both proto and JSON field names.
+func resolveFieldPath(md protoreflect.MessageDescriptor, fieldPath string) ([]protoreflect.FieldDescriptor, error) {
+	names := strings.Split(fieldPath, fieldPathDelimiter)
+	fds := make([]protoreflect.FieldDescriptor, 0, len(names))
+	for i, name := range names {
+		if md == nil {
+			return nil, fmt.Errorf("field %q: %s is not a message field", fieldPath, fds[i-1].Name())
+		}
+
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("field %q not found in %s, %w", fieldPath, md.FullName(), errFieldNotFound)
+		}
+
+		fds = append(fds, fd)
+		md = nil
+		if !fd.IsList() && !fd.IsMap() {
+			md = fd.Message()
+		}
+	}
+
+	return fds, nil
+}
+
+func isFieldNotFound(err error) bool {
+	return errors.Is(err, errFieldNotFound)
+}
+
+// fieldPathName returns the proto name form of resolved field descriptors.
+func fieldPathName(fds []protoreflect.FieldDescriptor) string {
+	names := make([]string, 0, len(fds))
+	for _, fd := range fds {
+		names = append(names, string(fd.Name()))
+	}
+
+	return strings.Join(names, fieldPathDelimiter)
+}
+
+// lookupField resolves a dotted field path to the message holding the last
+// field. Intermediate messages are allocated when allocate is set, otherwise a
+// nil message is returned for an unset parent.
+func lookupField(msg protoreflect.Message, fieldPath string, allocate bool) (protoreflect.Message, protoreflect.FieldDescriptor, error) {
+	fds, err := resolveFieldPath(msg.Descriptor(), fieldPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := fds[len(fds)-1]
+	for _, fd := range fds[:len(fds)-1] {
+		if !allocate && !msg.Has(fd) {
+			return nil, last, nil
+		}
+		msg = msg.Mutable(fd).Message()
+	}
+
+	return msg, last, nil
+}
+
+// setFieldPath parses val and stores it in the field at fieldPath.
+func setFieldPath(msg protoreflect.Message, fieldPath, val string) error {
+	parent, fd, err := lookupField(msg, fieldPath, true)
+	if err != nil {
+		return err
+	}
+
+	if fd.IsList() || fd.IsMap() || fd.Message() != nil {
+		return fmt.Errorf("field %q: %s fields cannot be bound from a single value", fieldPath, fd.Kind())
+	}
+
+	v, err := parseValue(fd, val)
+	if err != nil {
+		return fmt.Errorf("field %q: %w", fieldPath, err)
+	}
+	parent.Set(fd, v)
+
+	return nil
+}
+
+// getFieldPath formats the value of the field at fieldPath, returning an empty
+// string when the field or one of its parents is unset.
+func getFieldPath(msg protoreflect.Message, fieldPath string) (string, error) {
+	parent, fd, err := lookupField(msg, fieldPath, false)
+	if err != nil || parent == nil {
+		return "", err
+	}
+
+	if fd.IsList() || fd.IsMap() || fd.Message() != nil {
+		return "", fmt.Errorf("field %q: %s fields cannot be bound to a single value", fieldPath, fd.Kind())
+	}
+
+	if fd.HasPresence() && !parent.Has(fd) {
+		return "", nil
+	}
+
+	return formatValue(fd, parent.Get(fd))
+}
+
+// parseField parses the string form of a scalar, enum or well-known message
+// value of fd, newValue allocating the message to fill in.
+func parseField(fd protoreflect.FieldDescriptor, newValue func() protoreflect.Value, val string) (protoreflect.Value, error) {
+	if fd.Message() == nil {
+		return parseValue(fd, val)
+	}
+
+	v := newValue()
+	if err := parseWellKnown(v.Message(), val); err != nil {
+		return protoreflect.Value{}, err
+	}
+
+	return v, nil
+}
+
+// parseValue parses the string form of a singular scalar or enum value.
+func parseValue(fd protoreflect.FieldDescriptor, val string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(val), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(val)
+		}
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing bytes: %v", err)
+		}
+		return protoreflect.ValueOfBytes(b), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing bool: %v", err)
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(val)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing enum %s: unknown value %q", fd.Enum().FullName(), val)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing int: %v", err)
+		}
+		return protoreflect.ValueOfInt32(int32(n)), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing int: %v", err)
+		}
+		return protoreflect.ValueOfInt64(n), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing uint: %v", err)
+		}
+		return protoreflect.ValueOfUint32(uint32(n)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing uint: %v", err)
+		}
+		return protoreflect.ValueOfUint64(n), nil
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(val, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing float: %v", err)
+		}
+		return protoreflect.ValueOfFloat32(float32(f)), nil
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("parsing float: %v", err)
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind: %v", fd.Kind())
+	}
+}
+
+// parseWellKnown parses the string form of a well-known message type into m:
+// RFC 3339 timestamps, durations such as "1.5s", comma-separated field mask
+// paths and the value of wrapper types.
+func parseWellKnown(m protoreflect.Message, val string) error {
+	md := m.Descriptor()
+	switch md.FullName() {
+	case timestampName, durationName:
+		return protojson.Unmarshal([]byte(strconv.Quote(val)), m.Interface())
+	case fieldMaskName:
+		paths := m.Mutable(md.Fields().ByName("paths")).List()
+		for _, path := range strings.Split(val, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths.Append(protoreflect.ValueOfString(path))
+			}
+		}
+		return nil
+	case doubleValueName, floatValueName, int64ValueName, uint64ValueName, int32ValueName,
+		uint32ValueName, boolValueName, stringValueName, bytesValueName:
+		fd := md.Fields().ByName("value")
+		v, err := parseValue(fd, val)
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	default:
+		return fmt.Errorf("unsupported message type: %s", md.FullName())
+	}
+}
+
+// formatField is the inverse of parseField.
+func formatField(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, error) {
+	if fd.Message() == nil {
+		return formatValue(fd, v)
+	}
+
+	return formatWellKnown(v.Message())
+}
+
+// formatWellKnown is the inverse of parseWellKnown.
+func formatWellKnown(m protoreflect.Message) (string, error) {
+	md := m.Descriptor()
+	switch md.FullName() {
+	case timestampName, durationName:
+		b, err := protojson.Marshal(m.Interface())
+		if err != nil {
+			return "", err
+		}
+		return strconv.Unquote(string(b))
+	case fieldMaskName:
+		list := m.Get(md.Fields().ByName("paths")).List()
+		paths := make([]string, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			paths = append(paths, list.Get(i).String())
+		}
+		return strings.Join(paths, ","), nil
+	case doubleValueName, floatValueName, int64ValueName, uint64ValueName, int32ValueName,
+		uint32ValueName, boolValueName, stringValueName, bytesValueName:
+		fd := md.Fields().ByName("value")
+		return formatValue(fd, m.Get(fd))
+	default:
+		return "", fmt.Errorf("message type %s cannot be encoded as a single value", md.FullName())
+	}
+}
+
+func isWellKnown(md protoreflect.MessageDescriptor) bool {
+	switch md.FullName() {
+	case timestampName, durationName, fieldMaskName, doubleValueName, floatValueName, int64ValueName,
+		uint64ValueName, int32ValueName, uint32ValueName, boolValueName, stringValueName, bytesValueName:
+		return true
+	default:
+		return false
+	}
+}
+
+// formatValue is the inverse of parseValue.
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return v.String(), nil
+	case protoreflect.BytesKind:
+		return base64.URLEncoding.EncodeToString(v.Bytes()), nil
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool()), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), nil
+		}
+		return strconv.FormatInt(int64(v.Enum()), 10), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported field kind: %v", fd.Kind())
+	}
+}
@@ -0,0 +1,193 @@
+package binder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// isForm reports whether mediaType is an HTML form body.
+func isForm(mediaType string) bool {
+	switch option.ContentType(mediaType) {
+	case option.ContentTypeFormURLEncoded, option.ContentTypeMultipartForm:
+		return true
+	default:
+		return false
+	}
+}
+
+// bindForm populates the part of the request selected by the google.api.http
+// body from an application/x-www-form-urlencoded or multipart/form-data body.
+// Form fields follow the conventions of BindQuery, keyed relative to a body
+// field that is a message, and the files of a multipart body populate bytes
+// and google.api.HttpBody fields.
+func (d *RequestDecoder) bindForm(v interface{}, contentType string) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%v, %w", err, potErrors.ErrGeneralBadRequest)
+	}
+
+	body := d.Request.Body
+	if d.Opts.MaxFormSize > 0 {
+		body = http.MaxBytesReader(nil, body, d.Opts.MaxFormSize)
+	}
+
+	var (
+		values url.Values
+		files  map[string][]*multipart.FileHeader
+	)
+	if option.ContentType(mediaType) == option.ContentTypeFormURLEncoded {
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return formError(err)
+		}
+
+		if values, err = url.ParseQuery(string(content)); err != nil {
+			return fmt.Errorf("%v, %w", err, potErrors.ErrGeneralBadRequest)
+		}
+	} else {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("multipart boundary is missing, %w", potErrors.ErrGeneralBadRequest)
+		}
+
+		form, err := multipart.NewReader(body, boundary).ReadForm(d.Opts.MaxFormMemory)
+		if err != nil {
+			return formError(err)
+		}
+		defer form.RemoveAll()
+
+		values, files = form.Value, form.File
+	}
+
+	msg := v.(protoreflect.ProtoMessage).ProtoReflect()
+	for key, vals := range values {
+		fds, mapKey, isMapKey, err := resolveFormKey(msg, d.Opts.Body, key)
+		if isFieldNotFound(err) {
+			continue
+		}
+		if err == nil {
+			err = populateQueryField(msg, fds, mapKey, isMapKey, vals)
+		}
+		if err != nil {
+			return fmt.Errorf("binding form field %q: %v, %w", key, err, potErrors.ErrGeneralBadRequest)
+		}
+	}
+
+	for key, headers := range files {
+		fds, _, isMapKey, err := resolveFormKey(msg, d.Opts.Body, key)
+		if isFieldNotFound(err) {
+			continue
+		}
+		if err == nil && isMapKey {
+			err = errors.New("file cannot be bound to a map entry")
+		}
+		if err == nil {
+			err = populateFileField(msg, fds, headers)
+		}
+		if err != nil {
+			return fmt.Errorf("binding form file %q: %v, %w", key, err, potErrors.ErrGeneralBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// resolveFormKey resolves a form key relative to the body field when it is a
+// message. Any other body field is only populated by the key of its name, and
+// a "*" body by the keys of all the fields of the request.
+func resolveFormKey(msg protoreflect.Message, body, key string) ([]protoreflect.FieldDescriptor, string, bool, error) {
+	fieldPath, mapKey, isMapKey := splitMapKey(key)
+	if body != bodyWildcard {
+		fd, err := bodyField(msg, body)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			fieldPath = body + fieldPathDelimiter + fieldPath
+		}
+	}
+
+	fds, err := resolveFieldPath(msg.Descriptor(), fieldPath)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if body != bodyWildcard && string(fds[0].Name()) != body {
+		return nil, "", false, fmt.Errorf("field %q is not part of the body, %w", key, errFieldNotFound)
+	}
+
+	return fds, mapKey, isMapKey, nil
+}
+
+// populateFileField stores the content of the files into the bytes or
+// google.api.HttpBody field at the end of fds, allocating the intermediate
+// messages. An HttpBody gets the Content-Type of the file part.
+func populateFileField(msg protoreflect.Message, fds []protoreflect.FieldDescriptor, headers []*multipart.FileHeader) error {
+	for _, fd := range fds[:len(fds)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+
+	fd := fds[len(fds)-1]
+	isBytes := fd.Kind() == protoreflect.BytesKind
+	isHttpBody := fd.Message() != nil && fd.Message().FullName() == httpBodyName
+	if fd.IsMap() || !isBytes && !isHttpBody {
+		return fmt.Errorf("field %s cannot hold a file", fd.Name())
+	}
+	if !fd.IsList() && len(headers) > 1 {
+		return fmt.Errorf("field %s is not repeated but got %d files", fd.Name(), len(headers))
+	}
+
+	for _, header := range headers {
+		content, err := readFile(header)
+		if err != nil {
+			return err
+		}
+
+		contentType := header.Header.Get(option.ContentTypeHeader)
+		switch {
+		case isBytes && fd.IsList():
+			msg.Mutable(fd).List().Append(protoreflect.ValueOfBytes(content))
+		case isBytes:
+			msg.Set(fd, protoreflect.ValueOfBytes(content))
+		case fd.IsList():
+			list := msg.Mutable(fd).List()
+			val := list.NewElement()
+			setHttpBody(val.Message(), contentType, content)
+			list.Append(val)
+		default:
+			setHttpBody(msg.Mutable(fd).Message(), contentType, content)
+		}
+	}
+
+	return nil
+}
+
+func readFile(header *multipart.FileHeader) ([]byte, error) {
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// formError reports a form body exceeding the limits of the options as 413
+// Request Entity Too Large and any other read error as 400 Bad Request.
+func formError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || errors.Is(err, multipart.ErrMessageTooLarge) {
+		return fmt.Errorf("%v, %w", err, potErrors.ErrGeneralRequestEntityTooLarge)
+	}
+
+	return fmt.Errorf("%v, %w", err, potErrors.ErrGeneralBadRequest)
+}
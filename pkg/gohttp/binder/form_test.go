@@ -0,0 +1,229 @@
+package binder
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	_ "google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// uploadMessage describes a request with the fields a form can populate:
+//
+//	message Upload {
+//	  string name = 1;
+//	  repeated string tags = 2;
+//	  bytes data = 3;
+//	  google.api.HttpBody file = 4;
+//	  repeated google.api.HttpBody files = 5;
+//	}
+func uploadMessage(t *testing.T) protoreflect.MessageType {
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    label.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/upload.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/api/httpbody.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Upload"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("tags", 2, repeated, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("data", 3, optional, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				field("file", 4, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.api.HttpBody"),
+				field("files", 5, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.api.HttpBody"),
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dynamicpb.NewMessageType(file.Messages().Get(0))
+}
+
+type formFile struct {
+	key, filename, contentType, content string
+}
+
+// multipartBody writes values and files as a multipart/form-data body,
+// returning it with its content type.
+func multipartBody(t *testing.T, values [][2]string, files []formFile) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, kv := range values {
+		if err := w.WriteField(kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+f.key+`"; filename="`+f.filename+`"`)
+		header.Set(option.ContentTypeHeader, f.contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(f.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf, w.FormDataContentType()
+}
+
+func TestRequestDecoderBindForm(t *testing.T) {
+	upload := uploadMessage(t)
+
+	tests := []struct {
+		name       string
+		msg        protoreflect.MessageType
+		body       string
+		urlencoded string
+		values     [][2]string
+		files      []formFile
+		opts       []option.BinderOption
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "urlencoded",
+			msg:        upload,
+			urlencoded: "name=n&tags=a&tags=b&unknown=x",
+			want:       `{"name":"n","tags":["a","b"]}`,
+		},
+		{
+			name:       "urlencoded body field",
+			msg:        (&descriptorpb.FieldDescriptorProto{}).ProtoReflect().Type(),
+			body:       "options",
+			urlencoded: "packed=true&jstype=JS_STRING&name=ignored",
+			want:       `{"options":{"packed":true,"jstype":"JS_STRING"}}`,
+		},
+		{
+			name:   "multipart values and files",
+			msg:    upload,
+			values: [][2]string{{"name", "n"}},
+			files: []formFile{
+				{key: "data", filename: "a.bin", contentType: "application/octet-stream", content: "hi"},
+				{key: "file", filename: "a.txt", contentType: "text/plain", content: "hi"},
+				{key: "files", filename: "b.csv", contentType: "text/csv", content: "a,b"},
+				{key: "files", filename: "c.csv", contentType: "text/csv", content: "c,d"},
+			},
+			want: `{"name":"n","data":"aGk=","file":{"contentType":"text/plain","data":"aGk="},` +
+				`"files":[{"contentType":"text/csv","data":"YSxi"},{"contentType":"text/csv","data":"Yyxk"}]}`,
+		},
+		{
+			name:    "file into a string field",
+			msg:     upload,
+			files:   []formFile{{key: "name", filename: "a.txt", contentType: "text/plain", content: "hi"}},
+			wantErr: potErrors.ErrGeneralBadRequest,
+		},
+		{
+			name: "several files into a single field",
+			msg:  upload,
+			files: []formFile{
+				{key: "file", filename: "a.txt", contentType: "text/plain", content: "a"},
+				{key: "file", filename: "b.txt", contentType: "text/plain", content: "b"},
+			},
+			wantErr: potErrors.ErrGeneralBadRequest,
+		},
+		{
+			name:    "too large",
+			msg:     upload,
+			files:   []formFile{{key: "data", filename: "a.bin", contentType: "application/octet-stream", content: strings.Repeat("x", 1024)}},
+			opts:    []option.BinderOption{option.WithMaxFormSize(512)},
+			wantErr: potErrors.ErrGeneralRequestEntityTooLarge,
+		},
+		{
+			name:       "larger than the default size",
+			msg:        upload,
+			urlencoded: "name=" + strings.Repeat("x", int(option.DefaultMaxFormSize)),
+			wantErr:    potErrors.ErrGeneralRequestEntityTooLarge,
+		},
+		{
+			name:       "limit lifted",
+			msg:        upload,
+			urlencoded: "name=" + strings.Repeat("x", int(option.DefaultMaxFormSize)),
+			opts:       []option.BinderOption{option.WithMaxFormSize(-1)},
+			want:       `{"name":"` + strings.Repeat("x", int(option.DefaultMaxFormSize)) + `"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.urlencoded != "" {
+				req = httptest.NewRequest(http.MethodPost, "/v1/upload", strings.NewReader(tt.urlencoded))
+				req.Header.Set(option.ContentTypeHeader, option.ContentTypeFormURLEncoded.String())
+			} else {
+				body, contentType := multipartBody(t, tt.values, tt.files)
+				req = httptest.NewRequest(http.MethodPost, "/v1/upload", body)
+				req.Header.Set(option.ContentTypeHeader, contentType)
+			}
+
+			body := tt.body
+			if body == "" {
+				body = bodyWildcard
+			}
+			opts := append([]option.BinderOption{option.WithBody(body)}, tt.opts...)
+
+			got := tt.msg.New().Interface()
+			err := NewRequestDecoder(req, opts...).BindBody(got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := tt.msg.New().Interface()
+			if err := protojson.Unmarshal([]byte(tt.want), want); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRequestDecoderBindFormMissingBoundary(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/upload", strings.NewReader(""))
+	req.Header.Set(option.ContentTypeHeader, option.ContentTypeMultipartForm.String())
+
+	err := NewRequestDecoder(req).BindBody(uploadMessage(t).New().Interface())
+	if !errors.Is(err, potErrors.ErrGeneralBadRequest) {
+		t.Fatalf("got error %v, want a bad request", err)
+	}
+}
@@ -31,12 +31,18 @@ func (e *RequestEncoder) BindHeader() {
 		e.Request.Header.Set(key, fmt.Sprintf("%v", val))
 	}
 
-	if shouldHaveBody(e.Request.Method) {
+	if e.Opts.Body != "" {
 		e.Request.Header.Set("Content-Type", e.Opts.ContentType.String())
 	} else {
 		e.Request.Header.Del("Content-Type")
 	}
 
+	// the reply is asked in the content type of the request, unless the call
+	// accepts another one such as a stream.
+	if e.Request.Header.Get("Accept") == "" {
+		e.Request.Header.Set("Accept", e.Opts.ContentType.String())
+	}
+
 	if e.Opts.Operation != "" {
 		e.Request.Header.Set("X-Operation", e.Opts.Operation)
 	}
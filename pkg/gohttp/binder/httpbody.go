@@ -0,0 +1,70 @@
+package binder
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrRawStream is returned when reporting an error in a stream of
+// google.api.HttpBody, whose raw body has no room for it.
+var ErrRawStream = errors.New("error cannot be sent in a raw body stream")
+
+const (
+	httpBodyContentTypeField protoreflect.Name = "content_type"
+	httpBodyDataField        protoreflect.Name = "data"
+
+	// defaultHttpBodyContentType is sent for a google.api.HttpBody without a
+	// content type.
+	defaultHttpBodyContentType = "application/octet-stream"
+)
+
+// httpBody returns the google.api.HttpBody selected by the google.api.http
+// body field of m, "*" being the whole message. A body of another type is
+// encoded by a codec instead. The returned message is mutable when mutable
+// is set, so that it can be populated from a raw body.
+func httpBody(m interface{}, field string, mutable bool) (protoreflect.Message, bool) {
+	protoMessage, ok := m.(protoreflect.ProtoMessage)
+	if !ok {
+		return nil, false
+	}
+
+	msg := protoMessage.ProtoReflect()
+	if field == bodyWildcard {
+		return msg, msg.Descriptor().FullName() == httpBodyName
+	}
+
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(field))
+	if fd == nil || fd.IsList() || fd.IsMap() || fd.Message() == nil || fd.Message().FullName() != httpBodyName {
+		return nil, false
+	}
+
+	if mutable {
+		return msg.Mutable(fd).Message(), true
+	}
+	return msg.Get(fd).Message(), true
+}
+
+// setHttpBody populates body with the raw payload and its content type.
+func setHttpBody(body protoreflect.Message, contentType string, data []byte) {
+	fields := body.Descriptor().Fields()
+	if contentType != "" {
+		body.Set(fields.ByName(httpBodyContentTypeField), protoreflect.ValueOfString(contentType))
+	}
+	if len(data) > 0 {
+		body.Set(fields.ByName(httpBodyDataField), protoreflect.ValueOfBytes(data))
+	}
+}
+
+// httpBodyContent returns the raw payload of body and its content type,
+// application/octet-stream when it is unset.
+func httpBodyContent(body protoreflect.Message) (string, []byte) {
+	fields := body.Descriptor().Fields()
+
+	contentType := body.Get(fields.ByName(httpBodyContentTypeField)).String()
+	if contentType == "" {
+		contentType = defaultHttpBodyContentType
+	}
+
+	return contentType, body.Get(fields.ByName(httpBodyDataField)).Bytes()
+}
@@ -0,0 +1,196 @@
+package binder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// fileMessage describes a request with a google.api.HttpBody field:
+//
+//	message File {
+//	  string name = 1;
+//	  google.api.HttpBody body = 2;
+//	}
+func fileMessage(t *testing.T) protoreflect.MessageType {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/file.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/api/httpbody.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("File"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}, {
+				Name:     proto.String("body"),
+				JsonName: proto.String("body"),
+				Number:   proto.Int32(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".google.api.HttpBody"),
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dynamicpb.NewMessageType(file.Messages().Get(0))
+}
+
+func TestHttpBodyRequest(t *testing.T) {
+	file := fileMessage(t)
+	withBody := func(hb *httpbody.HttpBody) proto.Message {
+		m := file.New()
+		m.Set(file.Descriptor().Fields().ByName("body"), protoreflect.ValueOfMessage(hb.ProtoReflect()))
+		return m.Interface()
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		in              proto.Message
+		wantContentType string
+		wantContent     string
+	}{
+		{
+			name:            "whole request",
+			body:            "*",
+			in:              &httpbody.HttpBody{ContentType: "text/csv", Data: []byte("a,b\n")},
+			wantContentType: "text/csv",
+			wantContent:     "a,b\n",
+		},
+		{
+			name:            "body field",
+			body:            "body",
+			in:              withBody(&httpbody.HttpBody{ContentType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}),
+			wantContentType: "image/png",
+			wantContent:     "\x89PNG",
+		},
+		{
+			name:            "no content type",
+			body:            "*",
+			in:              &httpbody.HttpBody{Data: []byte("raw")},
+			wantContentType: "application/octet-stream",
+			wantContent:     "raw",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/files", nil)
+			enc := NewRequestEncoder(req, option.WithBody(tt.body))
+			enc.BindHeader()
+			if err := enc.BindBody(tt.in); err != nil {
+				t.Fatal(err)
+			}
+
+			// the payload is sent as is, with its own content type.
+			if got := req.Header.Get("Content-Type"); got != tt.wantContentType {
+				t.Fatalf("got content type %s, want %s", got, tt.wantContentType)
+			}
+			content, _ := io.ReadAll(req.Body)
+			if string(content) != tt.wantContent {
+				t.Fatalf("got content %q, want %q", content, tt.wantContent)
+			}
+
+			req.Body = io.NopCloser(bytes.NewReader(content))
+			got := tt.in.ProtoReflect().New().Interface()
+			if err := NewRequestDecoder(req, option.WithBody(tt.body)).BindBody(got); err != nil {
+				t.Fatal(err)
+			}
+			want := proto.Clone(tt.in)
+			if hb, ok := httpBody(want, tt.body, true); ok {
+				setHttpBody(hb, tt.wantContentType, nil)
+			}
+			if !proto.Equal(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestHttpBodyResponse(t *testing.T) {
+	in := &httpbody.HttpBody{ContentType: "text/html; charset=utf-8", Data: []byte("<p>hi</p>")}
+
+	rec := httptest.NewRecorder()
+	// the negotiated content type does not apply to a raw body.
+	if err := NewResponseEncoder(rec, option.WithContentType(option.ContentTypeProtobuf)).BindBody(in); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get("Content-Type"); got != in.ContentType {
+		t.Fatalf("got content type %s, want %s", got, in.ContentType)
+	}
+	if got := rec.Body.String(); got != "<p>hi</p>" {
+		t.Fatalf("got body %q", got)
+	}
+
+	got := new(httpbody.HttpBody)
+	if err := NewResponseDecoder(rec.Result()).BindBody(got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, in) {
+		t.Fatalf("got %v, want %v", got, in)
+	}
+}
+
+func TestHttpBodyStream(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/files:download", nil)
+	enc := NewStreamEncoder(rec, req)
+
+	for _, chunk := range []string{"a,b\n", "c,d\n"} {
+		if err := enc.Send(&httpbody.HttpBody{ContentType: "text/csv", Data: []byte(chunk)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.SendError(map[string]string{"message": "boom"}); !errors.Is(err, ErrRawStream) {
+		t.Fatalf("got %v, want ErrRawStream", err)
+	}
+
+	// the chunks make up a single raw body.
+	if got := rec.Header().Get("Content-Type"); got != "text/csv" {
+		t.Fatalf("got content type %s, want text/csv", got)
+	}
+	if got := rec.Body.String(); got != "a,b\nc,d\n" {
+		t.Fatalf("got body %q", got)
+	}
+
+	dec := NewStreamDecoder(rec.Result())
+	defer dec.Close()
+
+	var content []byte
+	for {
+		hb := new(httpbody.HttpBody)
+		err := dec.RecvMsg(hb)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hb.GetContentType() != "text/csv" {
+			t.Fatalf("got content type %s, want text/csv", hb.GetContentType())
+		}
+		content = append(content, hb.GetData()...)
+	}
+	if string(content) != "a,b\nc,d\n" {
+		t.Fatalf("got content %q", content)
+	}
+}
@@ -0,0 +1,40 @@
+package binder
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/codec"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+)
+
+// NegotiateContentType returns the content type of the response body to a
+// request with the given Accept header: the registered codec type of highest
+// quality, JSON when the header accepts any type or no codec matches it.
+func NegotiateContentType(accept string) option.ContentType {
+	contentTypes := codec.ContentTypes()
+
+	best, bestQuality := option.ContentTypeApplicationJson, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		for _, contentType := range contentTypes {
+			if mt == contentType && quality > bestQuality {
+				best, bestQuality = option.ContentType(contentType), quality
+			}
+		}
+	}
+
+	return best
+}
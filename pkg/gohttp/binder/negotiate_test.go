@@ -0,0 +1,32 @@
+package binder
+
+import (
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   option.ContentType
+	}{
+		{accept: "", want: option.ContentTypeApplicationJson},
+		{accept: "*/*", want: option.ContentTypeApplicationJson},
+		{accept: "application/json", want: option.ContentTypeApplicationJson},
+		{accept: "application/x-protobuf", want: option.ContentTypeProtobuf},
+		{accept: "application/proto", want: option.ContentTypeProto},
+		{accept: "text/html, application/x-protobuf;q=0.5", want: option.ContentTypeProtobuf},
+		{accept: "application/json;q=0.4, application/x-protobuf;q=0.9", want: option.ContentTypeProtobuf},
+		{accept: "application/x-protobuf;q=0.4, application/json", want: option.ContentTypeApplicationJson},
+		{accept: "application/x-protobuf;q=x", want: option.ContentTypeApplicationJson},
+		{accept: "text/html", want: option.ContentTypeApplicationJson},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := NegotiateContentType(tt.accept); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
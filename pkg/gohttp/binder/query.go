@@ -2,60 +2,227 @@ package binder
 
 import (
 	"fmt"
-	"reflect"
+	"net/url"
+	"strings"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// BindQuery populates the request from the query string following the
+// grpc-gateway conventions: "a.b.c" keys for nested fields, repeated keys for
+// repeated fields, "m[key]" for map entries, enum names or numbers and the
+// string forms of well-known types. Fields bound by the path or the body and
+// unknown keys are ignored.
 func (d *RequestDecoder) BindQuery(v interface{}) error {
-	params := d.Request.URL.Query()
+	msg := v.(protoreflect.ProtoMessage).ProtoReflect()
 
-	val := reflect.ValueOf(v)
-	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("out must be a pointer to a struct")
+	bound, err := boundFields(d.Opts)
+	if err != nil {
+		return err
 	}
-	val = val.Elem()
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
-		fieldVal := val.Field(i)
-		tag := field.Tag.Get(structTag)
-		if tag == "" || !fieldVal.CanSet() {
+	for key, values := range d.Request.URL.Query() {
+		fieldPath, mapKey, isMapKey := splitMapKey(key)
+		fds, err := resolveFieldPath(msg.Descriptor(), fieldPath)
+		if isFieldNotFound(err) {
 			continue
 		}
+		if err != nil {
+			return fmt.Errorf("binding query parameter %q: %v, %w", key, err, errors.ErrGeneralBadRequest)
+		}
 
-		fieldName := parseTag(tag)
-		queryValue := params.Get(fieldName)
-		if queryValue == "" {
+		if isBoundField(fieldPathName(fds), bound) {
 			continue
 		}
 
-		if err := setFieldValue(fieldVal, queryValue, structTagDefaultValueDelimiter); err != nil {
-			return fmt.Errorf("error setting field %s: %v", field.Name, err)
+		if err := populateQueryField(msg, fds, mapKey, isMapKey, values); err != nil {
+			return fmt.Errorf("binding query parameter %q: %v, %w", key, err, errors.ErrGeneralBadRequest)
 		}
 	}
 
 	return nil
 }
 
+// BindQuery encodes the populated fields of the request that are not bound by
+// the path or the body into the query string, using the conventions decoded by
+// RequestDecoder.BindQuery.
 func (d *RequestEncoder) BindQuery(v interface{}) error {
-	val := reflect.ValueOf(v)
-	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("out must be a pointer to a struct")
+	msg := v.(protoreflect.ProtoMessage).ProtoReflect()
+
+	bound, err := boundFields(d.Opts)
+	if err != nil {
+		return err
 	}
-	val = val.Elem()
 
 	query := d.Request.URL.Query()
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
-		fieldVal := val.Field(i)
-		tag := field.Tag.Get(structTag)
-		if tag == "" || !fieldVal.CanSet() {
-			continue
+	if err := encodeQuery(query, msg, "", bound); err != nil {
+		return err
+	}
+
+	d.Request.URL.RawQuery = query.Encode()
+	return nil
+}
+
+// boundFields returns the field paths bound by the path template and the body,
+// which the query string cannot override.
+func boundFields(opts *option.BinderOptions) ([]string, error) {
+	var bound []string
+	if opts.PathTemplate != "" {
+		tmpl, err := httprule.Parse(opts.PathTemplate)
+		if err != nil {
+			return nil, err
 		}
+		bound = append(bound, tmpl.FieldPaths()...)
+	}
 
-		fieldName := parseTag(tag)
-		query.Add(fieldName, fmt.Sprintf("%v", fieldVal.Interface()))
+	if opts.Body != "" {
+		bound = append(bound, opts.Body)
+	}
+
+	return bound, nil
+}
+
+// populateQueryField parses the query values into the field at the end of fds,
+// allocating the intermediate messages.
+func populateQueryField(msg protoreflect.Message, fds []protoreflect.FieldDescriptor, mapKey string, isMapKey bool, values []string) error {
+	for _, fd := range fds[:len(fds)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+
+	fd := fds[len(fds)-1]
+	switch {
+	case fd.IsMap():
+		if !isMapKey {
+			return fmt.Errorf("map field %s requires the %s[key] syntax", fd.Name(), fd.Name())
+		}
+
+		key, err := parseValue(fd.MapKey(), mapKey)
+		if err != nil {
+			return err
+		}
+
+		entries := msg.Mutable(fd).Map()
+		val, err := parseField(fd.MapValue(), entries.NewValue, values[len(values)-1])
+		if err != nil {
+			return err
+		}
+		entries.Set(key.MapKey(), val)
+	case isMapKey:
+		return fmt.Errorf("field %s is not a map", fd.Name())
+	case fd.IsList():
+		list := msg.Mutable(fd).List()
+		for _, value := range values {
+			val, err := parseField(fd, list.NewElement, value)
+			if err != nil {
+				return err
+			}
+			list.Append(val)
+		}
+	default:
+		if len(values) > 1 {
+			return fmt.Errorf("field %s is not repeated but got %d values", fd.Name(), len(values))
+		}
+
+		val, err := parseField(fd, func() protoreflect.Value { return msg.NewField(fd) }, values[0])
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, val)
 	}
 
-	d.Request.URL.RawQuery = query.Encode()
 	return nil
 }
+
+// encodeQuery adds the populated fields of msg to query, prefix being the field
+// path of msg in the request.
+func encodeQuery(query url.Values, msg protoreflect.Message, prefix string, bound []string) error {
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		key := prefix + string(fd.Name())
+		if isBoundField(key, bound) {
+			return true
+		}
+
+		err = encodeQueryField(query, fd, v, key, bound)
+		return err == nil
+	})
+
+	return err
+}
+
+func encodeQueryField(query url.Values, fd protoreflect.FieldDescriptor, v protoreflect.Value, key string, bound []string) error {
+	switch {
+	case !hasQueryForm(fd):
+		// like the query parameters of the generated documents, the fields
+		// without a query string form are only sent in a body.
+		return nil
+	case fd.IsMap():
+		var err error
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			var val string
+			val, err = formatField(fd.MapValue(), mv)
+			if err != nil {
+				return false
+			}
+			query.Add(fmt.Sprintf("%s[%s]", key, k.String()), val)
+			return true
+		})
+		return err
+	case fd.IsList():
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			val, err := formatField(fd, list.Get(i))
+			if err != nil {
+				return err
+			}
+			query.Add(key, val)
+		}
+		return nil
+	case fd.Message() != nil && !isWellKnown(fd.Message()):
+		return encodeQuery(query, v.Message(), key+fieldPathDelimiter, bound)
+	default:
+		val, err := formatField(fd, v)
+		if err != nil {
+			return err
+		}
+		query.Add(key, val)
+		return nil
+	}
+}
+
+// hasQueryForm reports whether the values of fd can be written to the query
+// string, the values of repeated and map fields being scalars or well-known
+// types with a string form.
+func hasQueryForm(fd protoreflect.FieldDescriptor) bool {
+	switch {
+	case fd.IsMap():
+		fd = fd.MapValue()
+	case !fd.IsList():
+		return true
+	}
+
+	return fd.Message() == nil || isWellKnown(fd.Message())
+}
+
+// splitMapKey splits a "field[key]" query key.
+func splitMapKey(key string) (string, string, bool) {
+	open := strings.IndexByte(key, '[')
+	if open < 0 || !strings.HasSuffix(key, "]") {
+		return key, "", false
+	}
+
+	return key[:open], key[open+1 : len(key)-1], true
+}
+
+func isBoundField(fieldPath string, bound []string) bool {
+	for _, b := range bound {
+		if fieldPath == b || strings.HasPrefix(fieldPath, b+fieldPathDelimiter) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,269 @@
+package binder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// queryMessage describes a request with the map and well-known type fields a
+// query string can bind, and a map of messages it cannot:
+//
+//	message Query {
+//	  string reason = 1;
+//	  map<string, string> metadata = 2;
+//	  google.protobuf.Duration delay = 3;
+//	  google.protobuf.Timestamp time = 4;
+//	  map<string, Query> children = 5;
+//	}
+func queryMessage(t *testing.T) protoreflect.MessageType {
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    label.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	str, msg := descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/query.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/duration.proto", "google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Query"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("reason", 1, optional, str, ""),
+				field("metadata", 2, repeated, msg, ".test.Query.MetadataEntry"),
+				field("delay", 3, optional, msg, ".google.protobuf.Duration"),
+				field("time", 4, optional, msg, ".google.protobuf.Timestamp"),
+				field("children", 5, repeated, msg, ".test.Query.ChildrenEntry"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name:    proto.String("MetadataEntry"),
+				Field:   []*descriptorpb.FieldDescriptorProto{field("key", 1, optional, str, ""), field("value", 2, optional, str, "")},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}, {
+				Name:    proto.String("ChildrenEntry"),
+				Field:   []*descriptorpb.FieldDescriptorProto{field("key", 1, optional, str, ""), field("value", 2, optional, msg, ".test.Query")},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dynamicpb.NewMessageType(file.Messages().Get(0))
+}
+
+// newMessage returns a message of type mt decoded from its JSON form.
+func newMessage(t *testing.T, mt protoreflect.MessageType, content string) proto.Message {
+	m := mt.New().Interface()
+	if err := protojson.Unmarshal([]byte(content), m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestRequestDecoderBindQuery(t *testing.T) {
+	query, field := queryMessage(t), (&descriptorpb.FieldDescriptorProto{}).ProtoReflect().Type()
+	file := (&descriptorpb.FileDescriptorProto{}).ProtoReflect().Type()
+
+	tests := []struct {
+		name    string
+		msg     protoreflect.MessageType
+		query   string
+		opts    []option.BinderOption
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "nested and enum names",
+			msg:   field,
+			query: "name=f&label=LABEL_REPEATED&options.packed=true&options.jstype=JS_STRING",
+			want:  `{"name":"f","label":"LABEL_REPEATED","options":{"packed":true,"jstype":"JS_STRING"}}`,
+		},
+		{
+			name:  "enum number and json name",
+			msg:   field,
+			query: "type=9&jsonName=f",
+			want:  `{"type":"TYPE_STRING","jsonName":"f"}`,
+		},
+		{
+			name:  "repeated",
+			msg:   file,
+			query: "dependency=a.proto&dependency=b.proto&public_dependency=1&public_dependency=0",
+			want:  `{"dependency":["a.proto","b.proto"],"publicDependency":[1,0]}`,
+		},
+		{
+			name:  "map",
+			msg:   query,
+			query: "reason=R&metadata[k]=v&metadata[x]=y",
+			want:  `{"reason":"R","metadata":{"k":"v","x":"y"}}`,
+		},
+		{
+			name:  "well-known types",
+			msg:   query,
+			query: "delay=1.5s&time=2024-01-02T03:04:05Z",
+			want:  `{"delay":"1.5s","time":"2024-01-02T03:04:05Z"}`,
+		},
+		{
+			name:  "unknown key",
+			msg:   field,
+			query: "unknown=1&name=f",
+			want:  `{"name":"f"}`,
+		},
+		{
+			name:  "bound by the path",
+			msg:   field,
+			query: "name=f&number=3",
+			opts:  []option.BinderOption{option.WithPathTemplate("/v1/{name}")},
+			want:  `{"number":3}`,
+		},
+		{
+			name:  "bound by the body",
+			msg:   field,
+			query: "name=f&options.packed=true",
+			opts:  []option.BinderOption{option.WithBody("options")},
+			want:  `{"name":"f"}`,
+		},
+		{name: "invalid number", msg: field, query: "number=x", wantErr: true},
+		{name: "invalid enum", msg: field, query: "label=NOPE", wantErr: true},
+		{name: "several values", msg: field, query: "name=a&name=b", wantErr: true},
+		{name: "map without key", msg: query, query: "metadata=v", wantErr: true},
+		{name: "key of a non-map", msg: query, query: "reason[k]=v", wantErr: true},
+		{name: "invalid duration", msg: query, query: "delay=soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/x?"+tt.query, nil)
+			opts := append([]option.BinderOption{option.WithBody("")}, tt.opts...)
+
+			got := tt.msg.New().Interface()
+			err := NewRequestDecoder(req, opts...).BindQuery(got)
+			if tt.wantErr {
+				if !errors.Is(err, potErrors.ErrGeneralBadRequest) {
+					t.Fatalf("got error %v, want a bad request", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := newMessage(t, tt.msg, tt.want); !proto.Equal(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRequestEncoderBindQuery(t *testing.T) {
+	query, field := queryMessage(t), (&descriptorpb.FieldDescriptorProto{}).ProtoReflect().Type()
+	file := (&descriptorpb.FileDescriptorProto{}).ProtoReflect().Type()
+
+	tests := []struct {
+		name    string
+		msg     protoreflect.MessageType
+		in      string
+		opts    []option.BinderOption
+		want    string
+		decoded string // the message read back by the decoder, in by default
+	}{
+		{name: "zero values", msg: field, in: `{}`, want: ""},
+		{
+			name: "nested and enum",
+			msg:  field,
+			in:   `{"name":"f","label":"LABEL_REPEATED","options":{"packed":true}}`,
+			want: "label=LABEL_REPEATED&name=f&options.packed=true",
+		},
+		{
+			name: "repeated",
+			msg:  file,
+			in:   `{"dependency":["a.proto","b.proto"]}`,
+			want: "dependency=a.proto&dependency=b.proto",
+		},
+		{
+			name: "map",
+			msg:  query,
+			in:   `{"metadata":{"k":"v"}}`,
+			want: "metadata%5Bk%5D=v",
+		},
+		{
+			name: "well-known types",
+			msg:  query,
+			in:   `{"delay":"1.5s","time":"2024-01-02T03:04:05Z"}`,
+			want: "delay=1.500s&time=2024-01-02T03%3A04%3A05Z",
+		},
+		{
+			name: "bound fields",
+			msg:  field,
+			in:   `{"name":"f","number":3,"options":{"packed":true}}`,
+			opts: []option.BinderOption{option.WithPathTemplate("/v1/{name}"), option.WithBody("options")},
+			want: "number=3",
+		},
+		{
+			name:    "repeated messages",
+			msg:     file,
+			in:      `{"name":"a.proto","messageType":[{"name":"M"}]}`,
+			want:    "name=a.proto",
+			decoded: `{"name":"a.proto"}`,
+		},
+		{
+			name:    "map of messages",
+			msg:     query,
+			in:      `{"reason":"r","children":{"k":{"reason":"c"}}}`,
+			want:    "reason=r",
+			decoded: `{"reason":"r"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := newMessage(t, tt.msg, tt.in)
+			req := httptest.NewRequest(http.MethodGet, "/v1/x", nil)
+			opts := append([]option.BinderOption{option.WithBody("")}, tt.opts...)
+
+			if err := NewRequestEncoder(req, opts...).BindQuery(in); err != nil {
+				t.Fatal(err)
+			}
+			if req.URL.RawQuery != tt.want {
+				t.Fatalf("got %q, want %q", req.URL.RawQuery, tt.want)
+			}
+
+			// the decoder reads back the fields the encoder wrote.
+			got := tt.msg.New().Interface()
+			if err := NewRequestDecoder(req, opts...).BindQuery(got); err != nil {
+				t.Fatal(err)
+			}
+			want := in
+			if tt.decoded != "" {
+				want = newMessage(t, tt.msg, tt.decoded)
+			}
+			if len(tt.opts) == 0 && !proto.Equal(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package binder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// StreamEncoder writes the messages of a server-streaming response either as
+// server-sent events or as newline-delimited JSON, following the Accept header
+// of the request. A stream of google.api.HttpBody is written as the raw chunks
+// of a single body instead, typed by the content type of the first one.
+type StreamEncoder struct {
+	Opts           *option.BinderOptions
+	ResponseWriter http.ResponseWriter
+	ContentType    option.ContentType
+
+	started bool
+	raw     bool
+}
+
+// StreamDecoder reads the messages written by a StreamEncoder.
+type StreamDecoder struct {
+	Opts     *option.BinderOptions
+	Response *http.Response
+
+	reader *bufio.Reader
+}
+
+// streamFrame is the grpc-gateway envelope of newline-delimited JSON and
+// WebSocket stream messages.
+type streamFrame struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+func NewStreamEncoder(w http.ResponseWriter, r *http.Request, opts ...option.BinderOption) *StreamEncoder {
+	return &StreamEncoder{
+		Opts:           option.NewBinderOptions(opts...),
+		ResponseWriter: w,
+		ContentType:    negotiateStream(r.Header.Get(option.AcceptHeader)),
+	}
+}
+
+func NewStreamDecoder(r *http.Response, opts ...option.BinderOption) *StreamDecoder {
+	return &StreamDecoder{
+		Opts:     option.NewBinderOptions(opts...),
+		Response: r,
+		reader:   bufio.NewReader(r.Body),
+	}
+}
+
+// Started reports whether the stream headers have been written, after which
+// errors can only be reported in-band.
+func (e *StreamEncoder) Started() bool {
+	return e.started
+}
+
+func (e *StreamEncoder) Send(v interface{}) error {
+	if hb, ok := httpBody(v, responseBodyField(e.Opts), false); ok {
+		contentType, data := httpBodyContent(hb)
+		if !e.started {
+			e.ContentType, e.raw = option.ContentType(contentType), true
+		}
+
+		e.writeHeader()
+		return e.flush(data)
+	}
+
+	content, err := marshalBody(jsonCodec(e.Opts), v.(protoreflect.ProtoMessage), responseBodyField(e.Opts))
+	if err != nil {
+		return err
+	}
+
+	if e.ContentType == option.ContentTypeNDJSON {
+		return e.writeFrame(streamFrame{Result: content})
+	}
+
+	return e.writeEvent("", content)
+}
+
+// SendError reports an error after the stream has started, as an "error"
+// event or an ndjson error frame. A raw body stream cannot carry the error, so
+// it fails with ErrRawStream.
+func (e *StreamEncoder) SendError(v interface{}) error {
+	if e.raw {
+		return ErrRawStream
+	}
+
+	content, err := marshalError(v)
+	if err != nil {
+		return err
+	}
+
+	if e.ContentType == option.ContentTypeNDJSON {
+		return e.writeFrame(streamFrame{Error: content})
+	}
+
+	return e.writeEvent(streamErrorEvent, content)
+}
+
+// Finish ends a stream without error, writing the stream headers when no
+// message was sent so that the client reads an empty stream.
+func (e *StreamEncoder) Finish() {
+	e.writeHeader()
+}
+
+func (e *StreamEncoder) writeHeader() {
+	if e.started {
+		return
+	}
+	e.started = true
+
+	header := e.ResponseWriter.Header()
+	header.Set(option.ContentTypeHeader, e.ContentType.String())
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	e.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
+func (e *StreamEncoder) writeEvent(event string, data []byte) error {
+	e.writeHeader()
+
+	var buf bytes.Buffer
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+
+	return e.flush(buf.Bytes())
+}
+
+func (e *StreamEncoder) writeFrame(frame streamFrame) error {
+	e.writeHeader()
+
+	content, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+
+	return e.flush(append(content, '\n'))
+}
+
+func (e *StreamEncoder) flush(content []byte) error {
+	if _, err := e.ResponseWriter.Write(content); err != nil {
+		return err
+	}
+
+	if err := http.NewResponseController(e.ResponseWriter).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// RecvMsg reads the next message of the stream into v. It returns io.EOF at
+// the end of the stream and the decoded error when the server reported one.
+// A google.api.HttpBody receives the next chunk of the raw body, which does
+// not necessarily match the chunks sent by the server.
+func (d *StreamDecoder) RecvMsg(v interface{}) error {
+	if hb, ok := httpBody(v, responseBodyField(d.Opts), true); ok {
+		chunk := make([]byte, rawStreamChunkSize)
+		n, err := d.reader.Read(chunk)
+		if n == 0 {
+			if err == nil {
+				err = io.ErrNoProgress
+			}
+			return err
+		}
+
+		setHttpBody(hb, d.Response.Header.Get(option.ContentTypeHeader), chunk[:n])
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(d.Response.Header.Get(option.ContentTypeHeader))
+
+	var (
+		data []byte
+		err  error
+	)
+	switch option.ContentType(mediaType) {
+	case option.ContentTypeEventStream:
+		var event string
+		event, data, err = d.readEvent()
+		if err != nil {
+			return err
+		}
+		if event == streamErrorEvent {
+			return decodeError(data)
+		}
+	case option.ContentTypeNDJSON:
+		var frame streamFrame
+		frame, err = d.readFrame()
+		if err != nil {
+			return err
+		}
+		if frame.Error != nil {
+			return decodeError(frame.Error)
+		}
+		data = frame.Result
+	default:
+		// a stream ended before its headers were written has no content type.
+		if _, err := d.reader.Peek(1); err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("content-type is not supported, %w", potErrors.ErrGeneralUnsupportedMediaType)
+	}
+
+	return unmarshalBody(jsonCodec(d.Opts), data, v.(protoreflect.ProtoMessage), responseBodyField(d.Opts))
+}
+
+func (d *StreamDecoder) Close() error {
+	return d.Response.Body.Close()
+}
+
+// readEvent reads the next server-sent event carrying data, skipping comments
+// and keep-alive events. An event cut by the end of the body is dispatched.
+func (d *StreamDecoder) readEvent() (string, []byte, error) {
+	var (
+		event string
+		data  [][]byte
+	)
+
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			if err == io.EOF && len(data) != 0 {
+				return event, bytes.Join(data, []byte("\n")), nil
+			}
+			return "", nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		if len(line) == 0 {
+			if len(data) != 0 {
+				return event, bytes.Join(data, []byte("\n")), nil
+			}
+			event = ""
+			continue
+		}
+
+		field, value, _ := strings.Cut(string(line), ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, []byte(value))
+		}
+	}
+}
+
+func (d *StreamDecoder) readFrame() (streamFrame, error) {
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return streamFrame{}, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var frame streamFrame
+		if err := json.Unmarshal(line, &frame); err != nil {
+			return streamFrame{}, err
+		}
+
+		return frame, nil
+	}
+}
+
+// negotiateStream picks newline-delimited JSON when the client accepts it and
+// server-sent events otherwise.
+func negotiateStream(accept string) option.ContentType {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && option.ContentType(mediaType) == option.ContentTypeNDJSON {
+			return option.ContentTypeNDJSON
+		}
+	}
+
+	return option.ContentTypeEventStream
+}
@@ -2,32 +2,31 @@ package binder
 
 import (
 	"fmt"
-	"reflect"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func (d *RequestDecoder) BindParams(v interface{}) error {
-	val := reflect.ValueOf(v)
-	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("out must be a pointer to a struct")
+	if d.Opts.PathTemplate == "" {
+		return nil
 	}
-	val = val.Elem()
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
-		fieldVal := val.Field(i)
-		tag := field.Tag.Get(structTag)
-		if tag == "" || !fieldVal.CanSet() {
-			continue
-		}
+	tmpl, err := httprule.Parse(d.Opts.PathTemplate)
+	if err != nil {
+		return err
+	}
 
-		fieldName := parseTag(tag)
-		paramValue := d.Request.PathValue(fieldName)
+	msg := v.(protoreflect.ProtoMessage).ProtoReflect()
+	for _, fieldPath := range tmpl.FieldPaths() {
+		paramValue := d.Request.PathValue(fieldPath)
 		if paramValue == "" {
 			continue
 		}
 
-		if err := setFieldValue(fieldVal, paramValue, structTagDefaultValueDelimiter); err != nil {
-			return fmt.Errorf("error setting field %s: %v", field.Name, err)
+		if err := setFieldPath(msg, fieldPath, paramValue); err != nil {
+			return fmt.Errorf("binding path variable: %v, %w", err, errors.ErrGeneralBadRequest)
 		}
 	}
 
@@ -35,25 +34,25 @@ func (d *RequestDecoder) BindParams(v interface{}) error {
 }
 
 func (d *RequestEncoder) BindParams(v interface{}) error {
-	val := reflect.ValueOf(v)
-	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("out must be a pointer to a struct")
+	if d.Opts.PathTemplate == "" {
+		return nil
 	}
-	val = val.Elem()
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
-		fieldVal := val.Field(i)
-		tag := field.Tag.Get(structTag)
-		if tag == "" || !fieldVal.CanSet() {
-			continue
-		}
+	tmpl, err := httprule.Parse(d.Opts.PathTemplate)
+	if err != nil {
+		return err
+	}
 
-		fieldName := parseTag(tag)
+	msg := v.(protoreflect.ProtoMessage).ProtoReflect()
+	vars := make(map[string]string)
+	for _, fieldPath := range tmpl.FieldPaths() {
+		val, err := getFieldPath(msg, fieldPath)
+		if err != nil {
+			return err
+		}
 
-		val := fmt.Sprintf("%v", fieldVal.Interface())
-		d.Request.SetPathValue(fieldName, val)
+		vars[fieldPath] = val
 	}
 
-	return nil
+	return encodeURL(d.Request.URL, tmpl, vars)
 }
@@ -0,0 +1,147 @@
+package binder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestRequestDecoderBindParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		vars     map[string]string
+		want     proto.Message
+		wantErr  bool
+	}{
+		{
+			name:     "nested field",
+			template: "/v1/{source_context.file_name}/apis/{name}",
+			vars:     map[string]string{"source_context.file_name": "a.proto", "name": "a"},
+			want:     &apipb.Api{Name: "a", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}},
+		},
+		{
+			name:     "multi-segment variable",
+			template: "/v1/{name=apis/*}",
+			vars:     map[string]string{"name": "apis/a"},
+			want:     &apipb.Api{Name: "apis/a"},
+		},
+		{
+			name:     "integer and enum",
+			template: "/v1/fields/{number}/{kind}",
+			vars:     map[string]string{"number": "3", "kind": "TYPE_STRING"},
+			want:     &typepb.Field{Number: 3, Kind: typepb.Field_TYPE_STRING},
+		},
+		{
+			name:     "enum number",
+			template: "/v1/fields/{kind}",
+			vars:     map[string]string{"kind": "9"},
+			want:     &typepb.Field{Kind: typepb.Field_TYPE_STRING},
+		},
+		{
+			name:     "invalid integer",
+			template: "/v1/fields/{number}",
+			vars:     map[string]string{"number": "x"},
+			want:     &typepb.Field{},
+			wantErr:  true,
+		},
+		{
+			name:     "message field",
+			template: "/v1/{source_context}",
+			vars:     map[string]string{"source_context": "x"},
+			want:     &apipb.Api{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for fieldPath, val := range tt.vars {
+				req.SetPathValue(fieldPath, val)
+			}
+
+			got := tt.want.ProtoReflect().New().Interface()
+			err := NewRequestDecoder(req, option.WithPathTemplate(tt.template)).BindParams(got)
+			if tt.wantErr {
+				if !errors.Is(err, potErrors.ErrGeneralBadRequest) {
+					t.Fatalf("got error %v, want a bad request", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestEncoderBindParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		in       proto.Message
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "nested field",
+			template: "/v1/{source_context.file_name}/apis/{name}",
+			in:       &apipb.Api{Name: "a b", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}},
+			want:     "/base/v1/a.proto/apis/a%20b",
+		},
+		{
+			name:     "multi-segment variable",
+			template: "/v1/{name=apis/*}:get",
+			in:       &apipb.Api{Name: "apis/a"},
+			want:     "/base/v1/apis/a:get",
+		},
+		{
+			name:     "enum",
+			template: "/v1/fields/{kind}",
+			in:       &typepb.Field{Kind: typepb.Field_TYPE_STRING},
+			want:     "/base/v1/fields/TYPE_STRING",
+		},
+		{
+			name:     "unset parent",
+			template: "/v1/{source_context.file_name}",
+			in:       &apipb.Api{},
+			wantErr:  true,
+		},
+		{
+			name:     "value not matching the template",
+			template: "/v1/{name=apis/*}",
+			in:       &apipb.Api{Name: "a"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/base", nil)
+
+			err := NewRequestEncoder(req, option.WithPathTemplate(tt.template)).BindParams(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got path %s, want an error", req.URL.EscapedPath())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := req.URL.EscapedPath(); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package binder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WebSocketServerConn is the server side of a client- or bidi-streaming
+// method. Client messages arrive as plain JSON text frames, an empty frame
+// ending the client stream, and server messages are sent as result or error
+// envelopes.
+type WebSocketServerConn struct {
+	Opts *option.BinderOptions
+	Conn *websocket.Conn
+}
+
+// WebSocketClientConn is the client side of a WebSocketServerConn.
+type WebSocketClientConn struct {
+	Opts *option.BinderOptions
+	Conn *websocket.Conn
+
+	stop func() bool
+}
+
+func NewWebSocketServerConn(conn *websocket.Conn, opts ...option.BinderOption) *WebSocketServerConn {
+	return &WebSocketServerConn{
+		Opts: option.NewBinderOptions(opts...),
+		Conn: conn,
+	}
+}
+
+// DialWebSocket opens the WebSocket of the path template set by the options,
+// relative to baseURL. A rejected handshake is decoded as the server error and
+// the connection is closed once ctx is done.
+func DialWebSocket(ctx context.Context, baseURL string, opts ...option.BinderOption) (*WebSocketClientConn, error) {
+	req, err := http.NewRequest(http.MethodGet, baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewRequestEncoder(req, opts...)
+	enc.BindHeader()
+
+	tmpl, err := httprule.Parse(enc.Opts.PathTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if err := encodeURL(req.URL, tmpl, nil); err != nil {
+		return nil, err
+	}
+
+	switch req.URL.Scheme {
+	case "http":
+		req.URL.Scheme = "ws"
+	case "https":
+		req.URL.Scheme = "wss"
+	}
+
+	dialer := *websocket.DefaultDialer
+	if enc.Opts.Dialer != nil {
+		dialer = *enc.Opts.Dialer
+	}
+	if enc.Opts.DialContext != nil {
+		dialer.NetDialContext = enc.Opts.DialContext
+	}
+	conn, res, err := dialer.DialContext(ctx, req.URL.String(), req.Header)
+	if err != nil {
+		if res == nil || potErrors.ErrorMap[res.StatusCode] == nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		return nil, NewResponseDecoder(res, opts...).BindError()
+	}
+
+	return &WebSocketClientConn{
+		Opts: enc.Opts,
+		Conn: conn,
+		stop: context.AfterFunc(ctx, func() { conn.Close() }),
+	}, nil
+}
+
+// RecvMsg reads the next client message into v, returning io.EOF once the
+// client closed its side of the stream.
+func (c *WebSocketServerConn) RecvMsg(v interface{}) error {
+	_, data, err := c.Conn.ReadMessage()
+	if err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return io.EOF
+		}
+		return err
+	}
+
+	if len(data) == 0 {
+		return io.EOF
+	}
+
+	if err := unmarshalBody(jsonCodec(c.Opts), data, v.(protoreflect.ProtoMessage), bodyWildcard); err != nil {
+		return fmt.Errorf("%v, %w", err, potErrors.ErrGeneralBadRequest)
+	}
+
+	return nil
+}
+
+func (c *WebSocketServerConn) SendMsg(v interface{}) error {
+	content, err := marshalBody(jsonCodec(c.Opts), v.(protoreflect.ProtoMessage), responseBodyField(c.Opts))
+	if err != nil {
+		return err
+	}
+
+	return c.Conn.WriteJSON(streamFrame{Result: content})
+}
+
+// SendError reports an error to the client as an error envelope.
+func (c *WebSocketServerConn) SendError(v interface{}) error {
+	content, err := marshalError(v)
+	if err != nil {
+		return err
+	}
+
+	return c.Conn.WriteJSON(streamFrame{Error: content})
+}
+
+// Close ends the stream with a normal closure.
+func (c *WebSocketServerConn) Close() error {
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	if err := c.Conn.WriteMessage(websocket.CloseMessage, msg); err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		c.Conn.Close()
+		return err
+	}
+
+	return c.Conn.Close()
+}
+
+func (c *WebSocketClientConn) SendMsg(v interface{}) error {
+	content, err := marshalBody(jsonCodec(c.Opts), v.(protoreflect.ProtoMessage), bodyWildcard)
+	if err != nil {
+		return err
+	}
+
+	return c.Conn.WriteMessage(websocket.TextMessage, content)
+}
+
+// CloseSend ends the client side of the stream, the server messages can still
+// be received.
+func (c *WebSocketClientConn) CloseSend() error {
+	return c.Conn.WriteMessage(websocket.TextMessage, nil)
+}
+
+// RecvMsg reads the next server message into v. It returns io.EOF at the end
+// of the stream and the decoded error when the server reported one.
+func (c *WebSocketClientConn) RecvMsg(v interface{}) error {
+	_, data, err := c.Conn.ReadMessage()
+	if err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return io.EOF
+		}
+		return err
+	}
+
+	var frame streamFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return err
+	}
+
+	if frame.Error != nil {
+		return decodeError(frame.Error)
+	}
+
+	return unmarshalBody(jsonCodec(c.Opts), frame.Result, v.(protoreflect.ProtoMessage), responseBodyField(c.Opts))
+}
+
+func (c *WebSocketClientConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
@@ -0,0 +1,94 @@
+package binder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/transport"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// joinHandler replies to each client message with the names received so far,
+// then reports a not found error once the client closed its stream.
+func joinHandler(rw http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/names:join" {
+		NewResponseEncoder(rw).BindError(http.StatusNotFound, potErrors.New("no such stream"))
+		return
+	}
+
+	ws, err := (&websocket.Upgrader{}).Upgrade(rw, r, nil)
+	if err != nil {
+		return
+	}
+	conn := NewWebSocketServerConn(ws)
+	defer conn.Close()
+
+	joined := ""
+	for {
+		in := new(wrapperspb.StringValue)
+		if err := conn.RecvMsg(in); err != nil {
+			if err == io.EOF {
+				conn.SendError(potErrors.New("joined " + joined))
+			}
+			return
+		}
+		joined += in.GetValue()
+		conn.SendMsg(wrapperspb.String(joined))
+	}
+}
+
+func TestDialWebSocket(t *testing.T) {
+	tr := transport.NewHandlerTransport(http.HandlerFunc(joinHandler))
+	defer tr.Close()
+
+	conn, err := DialWebSocket(context.Background(), "http://"+transport.Host,
+		option.WithPathTemplate("/v1/names:join"), option.WithDialContext(tr.DialContext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, tt := range []struct{ in, want string }{{in: "a", want: "a"}, {in: "b", want: "ab"}} {
+		if err := conn.SendMsg(wrapperspb.String(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		got := new(wrapperspb.StringValue)
+		if err := conn.RecvMsg(got); err != nil {
+			t.Fatal(err)
+		}
+		if got.GetValue() != tt.want {
+			t.Fatalf("got %q, want %q", got.GetValue(), tt.want)
+		}
+	}
+
+	// the server still reports its error once the client stream is closed.
+	if err := conn.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	err = conn.RecvMsg(new(wrapperspb.StringValue))
+	potErr := &potErrors.Error{}
+	if !errors.As(err, &potErr) || potErr.Message != "joined ab" {
+		t.Fatalf("got %v, want the server error", err)
+	}
+	if err := conn.RecvMsg(new(wrapperspb.StringValue)); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestDialWebSocketRejected(t *testing.T) {
+	tr := transport.NewHandlerTransport(http.HandlerFunc(joinHandler))
+	defer tr.Close()
+
+	_, err := DialWebSocket(context.Background(), "http://"+transport.Host,
+		option.WithPathTemplate("/v1/other"), option.WithDialContext(tr.DialContext))
+	potErr := &potErrors.Error{}
+	if !errors.As(err, &potErr) || potErr.Message != "no such stream" {
+		t.Fatalf("got %v, want the handshake error", err)
+	}
+}
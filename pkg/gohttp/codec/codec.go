@@ -0,0 +1,92 @@
+// Package codec holds the registry of the codecs encoding request and
+// response bodies, looked up by content type.
+package codec
+
+import (
+	"mime"
+	"sync"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Codec encodes bodies of a content type.
+type Codec interface {
+	// Name identifies the codec, such as json.
+	Name() string
+	// ContentType is the media type of the bodies, such as application/json.
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// FieldCodec is implemented by the codecs encoding a google.api.http body
+// field that is not a message as its bare value, the way JSON encodes a
+// repeated field as an array. Other codecs encode it as its parent message
+// holding just that field.
+type FieldCodec interface {
+	Codec
+	MarshalField(m protoreflect.Message, fd protoreflect.FieldDescriptor) ([]byte, error)
+	UnmarshalField(data []byte, m protoreflect.Message, fd protoreflect.FieldDescriptor) error
+}
+
+var (
+	mu     sync.RWMutex
+	codecs []Codec
+)
+
+func init() {
+	Register(JSON{})
+	Register(protoCodec{contentType: option.ContentTypeProtobuf.String()})
+	Register(protoCodec{contentType: option.ContentTypeProto.String()})
+}
+
+// Register registers c for its content type, replacing the codec registered
+// for it. Codecs are usually registered from an init function.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, registered := range codecs {
+		if registered.ContentType() == c.ContentType() {
+			codecs[i] = c
+			return
+		}
+	}
+
+	codecs = append(codecs, c)
+}
+
+// ForContentType returns the codec registered for the media type of a
+// Content-Type header.
+func ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, c := range codecs {
+		if c.ContentType() == mediaType {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// ContentTypes returns the registered content types by order of registration,
+// JSON being the first one.
+func ContentTypes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	contentTypes := make([]string, 0, len(codecs))
+	for _, c := range codecs {
+		contentTypes = append(contentTypes, c.ContentType())
+	}
+
+	return contentTypes
+}
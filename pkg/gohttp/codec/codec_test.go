@@ -0,0 +1,167 @@
+package codec
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// textCodec is a codec of a content type not registered by default.
+type textCodec struct {
+	name string
+}
+
+func (c textCodec) Name() string                      { return c.name }
+func (textCodec) ContentType() string                 { return "text/plain" }
+func (textCodec) Marshal(interface{}) ([]byte, error) { return nil, nil }
+func (textCodec) Unmarshal([]byte, interface{}) error { return nil }
+
+// restoreCodecs restores the registered codecs at the end of the test.
+func restoreCodecs(t *testing.T) {
+	mu.RLock()
+	saved := slices.Clone(codecs)
+	mu.RUnlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		codecs = saved
+		mu.Unlock()
+	})
+}
+
+func TestForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+		wantOK      bool
+	}{
+		{contentType: "application/json", want: "json", wantOK: true},
+		{contentType: "application/json; charset=utf-8", want: "json", wantOK: true},
+		{contentType: "Application/JSON", want: "json", wantOK: true},
+		{contentType: "application/x-protobuf", want: "proto", wantOK: true},
+		{contentType: "application/proto", want: "proto", wantOK: true},
+		{contentType: "text/plain"},
+		{contentType: ""},
+		{contentType: "application/json; charset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			c, ok := ForContentType(tt.contentType)
+			if ok != tt.wantOK || (ok && c.Name() != tt.want) {
+				t.Fatalf("got %v, %v, want %s, %v", c, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	restoreCodecs(t)
+
+	Register(textCodec{name: "text"})
+	if c, ok := ForContentType("text/plain"); !ok || c.Name() != "text" {
+		t.Fatalf("got %v, %v, want the text codec", c, ok)
+	}
+
+	// registering a content type again replaces its codec in place.
+	Register(textCodec{name: "text2"})
+	if c, _ := ForContentType("text/plain"); c.Name() != "text2" {
+		t.Fatalf("got %s, want text2", c.Name())
+	}
+
+	want := []string{"application/json", "application/x-protobuf", "application/proto", "text/plain"}
+	if got := ContentTypes(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	in := &descriptorpb.FieldDescriptorProto{Name: proto.String("f"), TypeName: proto.String(".test.M")}
+
+	tests := []struct {
+		name    string
+		codec   Codec
+		wantKey string
+	}{
+		{name: "json", codec: JSON{}, wantKey: "typeName"},
+		{name: "json proto names", codec: JSON{MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}}, wantKey: "type_name"},
+		{name: "proto", codec: protoCodec{contentType: "application/x-protobuf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.codec.Marshal(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantKey != "" {
+				var fields map[string]interface{}
+				if err := json.Unmarshal(data, &fields); err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := fields[tt.wantKey]; !ok {
+					t.Fatalf("got %s, want a %q key", data, tt.wantKey)
+				}
+			}
+
+			out := new(descriptorpb.FieldDescriptorProto)
+			if err := tt.codec.Unmarshal(data, out); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(in, out) {
+				t.Fatalf("got %v, want %v", out, in)
+			}
+		})
+	}
+}
+
+func TestJSONField(t *testing.T) {
+	in := &descriptorpb.FileDescriptorProto{Name: proto.String("a.proto"), Dependency: []string{"b.proto", "c.proto"}}
+
+	tests := []struct {
+		name  string
+		field protoreflect.Name
+		in    *descriptorpb.FileDescriptorProto
+		want  string
+	}{
+		{name: "scalar", field: "name", in: in, want: `"a.proto"`},
+		{name: "repeated", field: "dependency", in: in, want: `["b.proto","c.proto"]`},
+		{name: "unpopulated repeated", field: "dependency", in: &descriptorpb.FileDescriptorProto{}, want: `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.in.ProtoReflect()
+			fd := msg.Descriptor().Fields().ByName(tt.field)
+
+			// the field is encoded as its bare value.
+			data, err := JSON{}.MarshalField(msg, fd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var value interface{}
+			if err := json.Unmarshal(data, &value); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := json.Marshal(value); string(got) != tt.want {
+				t.Fatalf("got %s, want %s", data, tt.want)
+			}
+
+			// and decoded back into its parent message.
+			out := new(descriptorpb.FileDescriptorProto)
+			if err := (JSON{}).UnmarshalField(data, out.ProtoReflect(), fd); err != nil {
+				t.Fatal(err)
+			}
+			want := msg.New()
+			if msg.Has(fd) {
+				want.Set(fd, msg.Get(fd))
+			}
+			if !proto.Equal(out, want.Interface()) {
+				t.Fatalf("got %v, want %v", out, want)
+			}
+		})
+	}
+}
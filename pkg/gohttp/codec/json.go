@@ -0,0 +1,94 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// JSON encodes proto messages with protojson and other values with
+// encoding/json. Registering a JSON value sets the protojson options of all
+// the services and clients:
+//
+//	codec.Register(codec.JSON{
+//		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+//		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+//	})
+type JSON struct {
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+var _ FieldCodec = JSON{}
+
+func (JSON) Name() string {
+	return "json"
+}
+
+func (JSON) ContentType() string {
+	return option.ContentTypeApplicationJson.String()
+}
+
+func (c JSON) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return c.MarshalOptions.Marshal(m)
+	}
+
+	return json.Marshal(v)
+}
+
+func (c JSON) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return c.UnmarshalOptions.Unmarshal(data, m)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (c JSON) MarshalField(m protoreflect.Message, fd protoreflect.FieldDescriptor) ([]byte, error) {
+	tmp := m.New()
+	if m.Has(fd) {
+		tmp.Set(fd, m.Get(fd))
+	}
+
+	// an unpopulated field is left out by protojson, marshal the empty
+	// message with EmitUnpopulated to get its zero value instead.
+	opts := c.MarshalOptions
+	opts.EmitUnpopulated = opts.EmitUnpopulated || !m.Has(fd)
+	content, err := opts.Marshal(tmp.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(content, &wrapper); err != nil {
+		return nil, err
+	}
+
+	if opts.UseProtoNames {
+		return wrapper[fd.TextName()], nil
+	}
+	return wrapper[fd.JSONName()], nil
+}
+
+func (c JSON) UnmarshalField(data []byte, m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	// protojson only decodes messages, so scalar, repeated and map fields are
+	// decoded through a wrapper object holding just that field.
+	wrapper, err := json.Marshal(map[string]json.RawMessage{fd.JSONName(): data})
+	if err != nil {
+		return err
+	}
+
+	tmp := m.New()
+	if err := c.UnmarshalOptions.Unmarshal(wrapper, tmp.Interface()); err != nil {
+		return err
+	}
+	if tmp.Has(fd) {
+		m.Set(fd, tmp.Get(fd))
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package codec
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// protoCodec encodes proto messages in the binary format.
+type protoCodec struct {
+	contentType string
+}
+
+func (protoCodec) Name() string {
+	return "proto"
+}
+
+func (c protoCodec) ContentType() string {
+	return c.contentType
+}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", v)
+	}
+
+	return proto.Marshal(m)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto message", v)
+	}
+
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{code: codes.OK, want: http.StatusOK},
+		{code: codes.Canceled, want: StatusClientClosedRequest},
+		{code: codes.InvalidArgument, want: http.StatusBadRequest},
+		{code: codes.FailedPrecondition, want: http.StatusBadRequest},
+		{code: codes.OutOfRange, want: http.StatusBadRequest},
+		{code: codes.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{code: codes.NotFound, want: http.StatusNotFound},
+		{code: codes.AlreadyExists, want: http.StatusConflict},
+		{code: codes.Aborted, want: http.StatusConflict},
+		{code: codes.PermissionDenied, want: http.StatusForbidden},
+		{code: codes.Unauthenticated, want: http.StatusUnauthorized},
+		{code: codes.ResourceExhausted, want: http.StatusTooManyRequests},
+		{code: codes.Unimplemented, want: http.StatusNotImplemented},
+		{code: codes.Unavailable, want: http.StatusServiceUnavailable},
+		{code: codes.Internal, want: http.StatusInternalServerError},
+		{code: codes.DataLoss, want: http.StatusInternalServerError},
+		{code: codes.Unknown, want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := HTTPStatusFromCode(tt.code); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       codes.Code
+	}{
+		{statusCode: http.StatusOK, want: codes.OK},
+		{statusCode: http.StatusNoContent, want: codes.OK},
+		{statusCode: http.StatusBadRequest, want: codes.InvalidArgument},
+		{statusCode: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{statusCode: http.StatusForbidden, want: codes.PermissionDenied},
+		{statusCode: http.StatusNotFound, want: codes.NotFound},
+		{statusCode: http.StatusConflict, want: codes.Aborted},
+		{statusCode: http.StatusTooManyRequests, want: codes.ResourceExhausted},
+		{statusCode: StatusClientClosedRequest, want: codes.Canceled},
+		{statusCode: http.StatusTeapot, want: codes.FailedPrecondition},
+		{statusCode: http.StatusInternalServerError, want: codes.Internal},
+		{statusCode: http.StatusBadGateway, want: codes.Unknown},
+		{statusCode: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{statusCode: http.StatusGatewayTimeout, want: codes.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.statusCode), func(t *testing.T) {
+			if got := CodeFromHTTPStatus(tt.statusCode); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "error with a code", err: fmt.Errorf("create, %w", NewCode(codes.AlreadyExists, "book exists")), want: http.StatusConflict},
+		{name: "code over general error", err: fmt.Errorf("%w", NewCode(codes.NotFound, "gone").WithInternalMessage("x")), want: http.StatusNotFound},
+		{name: "general error", err: fmt.Errorf("no book, %w", ErrGeneralNotFound), want: http.StatusNotFound},
+		{name: "grpc status error", err: status.Error(codes.PermissionDenied, "denied"), want: http.StatusForbidden},
+		{name: "error without code", err: New("boom"), want: http.StatusInternalServerError},
+		{name: "plain error", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := ParseErr(tt.err); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorGRPCStatus(t *testing.T) {
+	err := fmt.Errorf("create, %w", NewCode(codes.AlreadyExists, "book exists"))
+
+	// grpc servers report the error with its own code.
+	s, ok := status.FromError(err)
+	if !ok || s.Code() != codes.AlreadyExists || s.Message() != "create, book exists" {
+		t.Fatalf("got %v, want AlreadyExists: create, book exists", s)
+	}
+}
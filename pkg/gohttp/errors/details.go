@@ -0,0 +1,278 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Detail is a typed error detail, carried on the wire as the google.rpc
+// message it is modeled on. Details are errors themselves so that they can be
+// read back with errors.As on the *Error holding them:
+//
+//	var badRequest *errors.BadRequest
+//	if errors.As(err, &badRequest) {
+//		...
+//	}
+type Detail interface {
+	error
+	toProto() proto.Message
+}
+
+var (
+	_ Detail = &BadRequest{}
+	_ Detail = &ErrorInfo{}
+	_ Detail = &RetryInfo{}
+	_ Detail = &QuotaFailure{}
+	_ Detail = &PreconditionFailure{}
+)
+
+type (
+	// BadRequest is modeled on google.rpc.BadRequest.
+	BadRequest struct {
+		FieldViolations []FieldViolation
+	}
+
+	FieldViolation struct {
+		Field       string
+		Description string
+		Reason      string
+	}
+
+	// ErrorInfo is modeled on google.rpc.ErrorInfo.
+	ErrorInfo struct {
+		Reason   string
+		Domain   string
+		Metadata map[string]string
+	}
+
+	// RetryInfo is modeled on google.rpc.RetryInfo.
+	RetryInfo struct {
+		RetryDelay time.Duration
+	}
+
+	// QuotaFailure is modeled on google.rpc.QuotaFailure.
+	QuotaFailure struct {
+		Violations []QuotaViolation
+	}
+
+	QuotaViolation struct {
+		Subject     string
+		Description string
+	}
+
+	// PreconditionFailure is modeled on google.rpc.PreconditionFailure.
+	PreconditionFailure struct {
+		Violations []PreconditionViolation
+	}
+
+	PreconditionViolation struct {
+		Type        string
+		Subject     string
+		Description string
+	}
+)
+
+func (d *BadRequest) Error() string {
+	violations := make([]string, 0, len(d.FieldViolations))
+	for _, v := range d.FieldViolations {
+		violations = append(violations, v.Field+": "+v.Description)
+	}
+
+	return "bad request: " + strings.Join(violations, "; ")
+}
+
+func (d *ErrorInfo) Error() string {
+	return fmt.Sprintf("error info: %s (%s)", d.Reason, d.Domain)
+}
+
+func (d *RetryInfo) Error() string {
+	return fmt.Sprintf("retry after %s", d.RetryDelay)
+}
+
+func (d *QuotaFailure) Error() string {
+	violations := make([]string, 0, len(d.Violations))
+	for _, v := range d.Violations {
+		violations = append(violations, v.Subject+": "+v.Description)
+	}
+
+	return "quota failure: " + strings.Join(violations, "; ")
+}
+
+func (d *PreconditionFailure) Error() string {
+	violations := make([]string, 0, len(d.Violations))
+	for _, v := range d.Violations {
+		violations = append(violations, v.Type+" "+v.Subject+": "+v.Description)
+	}
+
+	return "precondition failure: " + strings.Join(violations, "; ")
+}
+
+func (d *BadRequest) toProto() proto.Message {
+	m := &errdetails.BadRequest{}
+	for _, v := range d.FieldViolations {
+		m.FieldViolations = append(m.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+			Reason:      v.Reason,
+		})
+	}
+
+	return m
+}
+
+func (d *ErrorInfo) toProto() proto.Message {
+	return &errdetails.ErrorInfo{
+		Reason:   d.Reason,
+		Domain:   d.Domain,
+		Metadata: d.Metadata,
+	}
+}
+
+func (d *RetryInfo) toProto() proto.Message {
+	return &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(d.RetryDelay),
+	}
+}
+
+func (d *QuotaFailure) toProto() proto.Message {
+	m := &errdetails.QuotaFailure{}
+	for _, v := range d.Violations {
+		m.Violations = append(m.Violations, &errdetails.QuotaFailure_Violation{
+			Subject:     v.Subject,
+			Description: v.Description,
+		})
+	}
+
+	return m
+}
+
+func (d *PreconditionFailure) toProto() proto.Message {
+	m := &errdetails.PreconditionFailure{}
+	for _, v := range d.Violations {
+		m.Violations = append(m.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        v.Type,
+			Subject:     v.Subject,
+			Description: v.Description,
+		})
+	}
+
+	return m
+}
+
+// fromProto converts a google.rpc detail message into its Detail, reporting
+// false for the messages without one.
+func fromProto(m proto.Message) (Detail, bool) {
+	switch m := m.(type) {
+	case *errdetails.BadRequest:
+		d := &BadRequest{}
+		for _, v := range m.GetFieldViolations() {
+			d.FieldViolations = append(d.FieldViolations, FieldViolation{
+				Field:       v.GetField(),
+				Description: v.GetDescription(),
+				Reason:      v.GetReason(),
+			})
+		}
+		return d, true
+	case *errdetails.ErrorInfo:
+		return &ErrorInfo{
+			Reason:   m.GetReason(),
+			Domain:   m.GetDomain(),
+			Metadata: m.GetMetadata(),
+		}, true
+	case *errdetails.RetryInfo:
+		return &RetryInfo{
+			RetryDelay: m.GetRetryDelay().AsDuration(),
+		}, true
+	case *errdetails.QuotaFailure:
+		d := &QuotaFailure{}
+		for _, v := range m.GetViolations() {
+			d.Violations = append(d.Violations, QuotaViolation{
+				Subject:     v.GetSubject(),
+				Description: v.GetDescription(),
+			})
+		}
+		return d, true
+	case *errdetails.PreconditionFailure:
+		d := &PreconditionFailure{}
+		for _, v := range m.GetViolations() {
+			d.Violations = append(d.Violations, PreconditionViolation{
+				Type:        v.GetType(),
+				Subject:     v.GetSubject(),
+				Description: v.GetDescription(),
+			})
+		}
+		return d, true
+	default:
+		return nil, false
+	}
+}
+
+// WithDetail attaches typed details to the error.
+func (e *Error) WithDetail(details ...Detail) *Error {
+	for _, d := range details {
+		a, err := anypb.New(d.toProto())
+		if err != nil {
+			// the google.rpc detail messages always marshal.
+			panic(err)
+		}
+		e.Details = append(e.Details, a)
+	}
+
+	return e
+}
+
+func (e *Error) WithBadRequest(violations ...FieldViolation) *Error {
+	return e.WithDetail(&BadRequest{FieldViolations: violations})
+}
+
+func (e *Error) WithErrorInfo(reason, domain string, metadata map[string]string) *Error {
+	return e.WithDetail(&ErrorInfo{Reason: reason, Domain: domain, Metadata: metadata})
+}
+
+func (e *Error) WithRetryInfo(retryDelay time.Duration) *Error {
+	return e.WithDetail(&RetryInfo{RetryDelay: retryDelay})
+}
+
+func (e *Error) WithQuotaFailure(violations ...QuotaViolation) *Error {
+	return e.WithDetail(&QuotaFailure{Violations: violations})
+}
+
+func (e *Error) WithPreconditionFailure(violations ...PreconditionViolation) *Error {
+	return e.WithDetail(&PreconditionFailure{Violations: violations})
+}
+
+// As finds the first detail of the error matching target, a pointer to one of
+// the Detail types.
+func (e *Error) As(target interface{}) bool {
+	tv := reflect.ValueOf(target)
+	if tv.Kind() != reflect.Pointer || tv.IsNil() {
+		return false
+	}
+	tv = tv.Elem()
+
+	for _, a := range e.Details {
+		m, err := a.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+
+		d, ok := fromProto(m)
+		if !ok {
+			continue
+		}
+
+		if dv := reflect.ValueOf(d); dv.Type().AssignableTo(tv.Type()) {
+			tv.Set(dv)
+			return true
+		}
+	}
+
+	return false
+}
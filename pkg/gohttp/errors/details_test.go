@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestErrorDetails(t *testing.T) {
+	other, err := anypb.New(wrapperspb.String("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := NewCode(codes.InvalidArgument, "invalid book").
+		WithDetails(other).
+		WithBadRequest(FieldViolation{Field: "title", Description: "must not be empty", Reason: "EMPTY"}).
+		WithErrorInfo("INVALID", "books.example.com", map[string]string{"id": "1"}).
+		WithRetryInfo(1500 * time.Millisecond).
+		WithQuotaFailure(QuotaViolation{Subject: "user:1", Description: "too many books"}).
+		WithPreconditionFailure(PreconditionViolation{Type: "TOS", Subject: "user:1", Description: "not accepted"})
+
+	// the client decodes the error from its JSON body.
+	b, err := json.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := new(Error)
+	if err := json.Unmarshal(b, received); err != nil {
+		t.Fatal(err)
+	}
+	if received.Code != codes.InvalidArgument || received.Message != "invalid book" || len(received.Details) != 6 {
+		t.Fatalf("got %+v from %s", received, b)
+	}
+
+	wrapped := fmt.Errorf("create, %w", received)
+	tests := []struct {
+		name   string
+		target Detail
+		want   Detail
+	}{
+		{
+			name:   "bad request",
+			target: new(BadRequest),
+			want:   &BadRequest{FieldViolations: []FieldViolation{{Field: "title", Description: "must not be empty", Reason: "EMPTY"}}},
+		},
+		{
+			name:   "error info",
+			target: new(ErrorInfo),
+			want:   &ErrorInfo{Reason: "INVALID", Domain: "books.example.com", Metadata: map[string]string{"id": "1"}},
+		},
+		{
+			name:   "retry info",
+			target: new(RetryInfo),
+			want:   &RetryInfo{RetryDelay: 1500 * time.Millisecond},
+		},
+		{
+			name:   "quota failure",
+			target: new(QuotaFailure),
+			want:   &QuotaFailure{Violations: []QuotaViolation{{Subject: "user:1", Description: "too many books"}}},
+		},
+		{
+			name:   "precondition failure",
+			target: new(PreconditionFailure),
+			want:   &PreconditionFailure{Violations: []PreconditionViolation{{Type: "TOS", Subject: "user:1", Description: "not accepted"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := reflect.New(reflect.TypeOf(tt.target))
+			if !errors.As(wrapped, target.Interface()) {
+				t.Fatalf("got no %T detail in %v", tt.target, wrapped)
+			}
+			if got := target.Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorDetailsMissing(t *testing.T) {
+	err := fmt.Errorf("create, %w", New("invalid book").WithRetryInfo(time.Second))
+
+	var badRequest *BadRequest
+	if errors.As(err, &badRequest) {
+		t.Fatalf("got %+v, want no bad request detail", badRequest)
+	}
+}
+
+func TestErrorUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantCode    codes.Code
+		wantDetails int
+	}{
+		{
+			name:     "error",
+			data:     `{"data":null,"message":"invalid book"}`,
+			wantCode: codes.OK,
+		},
+		{
+			name:        "status",
+			data:        `{"code":3,"message":"invalid book","details":[{"@type":"type.googleapis.com/google.protobuf.StringValue","value":"x"}]}`,
+			wantCode:    codes.InvalidArgument,
+			wantDetails: 1,
+		},
+		{
+			name:     "unknown detail",
+			data:     `{"code":3,"message":"invalid book","details":[{"@type":"type.googleapis.com/test.Unknown"}]}`,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(Error)
+			if err := json.Unmarshal([]byte(tt.data), got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Code != tt.wantCode || got.Message != "invalid book" || len(got.Details) != tt.wantDetails {
+				t.Fatalf("got %+v", got)
+			}
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package errors
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 var _ error = &Error{}
 
 type Error struct {
-	Data            interface{} `json:"data"`
-	Message         string      `json:"message"`
-	InternalMessage string      `json:"-"`
+	Data            interface{}  `json:"data"`
+	Message         string       `json:"message"`
+	Reason          string       `json:"reason,omitempty"`
+	InternalMessage string       `json:"-"`
+	Code            codes.Code   `json:"-"`
+	Details         []*anypb.Any `json:"-"`
 }
 
 // Error implements error.
@@ -22,11 +31,84 @@ func (e *Error) Error() string {
 	return e.Message + ": " + e.InternalMessage
 }
 
+// MarshalJSON adds the code and the details of the error to its JSON body, the
+// details encoded as google.protobuf.Any.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type plain Error
+
+	details := make([]json.RawMessage, 0, len(e.Details))
+	for _, d := range e.Details {
+		b, err := protojson.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, b)
+	}
+
+	return json.Marshal(struct {
+		*plain
+		Code    codes.Code        `json:"code,omitempty"`
+		Details []json.RawMessage `json:"details,omitempty"`
+	}{(*plain)(e), e.Code, details})
+}
+
+// UnmarshalJSON is the inverse of MarshalJSON, also accepting the JSON form of
+// a google.rpc.Status. Details of unknown types are dropped.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	type plain Error
+
+	v := struct {
+		*plain
+		Code    codes.Code        `json:"code"`
+		Details []json.RawMessage `json:"details"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	e.Code = v.Code
+	e.Details = nil
+	for _, b := range v.Details {
+		a := &anypb.Any{}
+		if err := protojson.Unmarshal(b, a); err != nil {
+			continue
+		}
+		e.Details = append(e.Details, a)
+	}
+
+	// a google.rpc.Status carries the reason in its ErrorInfo.
+	if info := (*ErrorInfo)(nil); e.Reason == "" && e.As(&info) {
+		e.Reason = info.Reason
+	}
+
+	return nil
+}
+
+// Is reports whether the error has the reason of target, so that an error
+// decoded from a response matches the registered sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Reason != "" && e.Reason == t.Reason
+}
+
+// GRPCStatus reports the error as a grpc status, so that it maps to the same
+// code when returned from a grpc server.
+func (e *Error) GRPCStatus() *status.Status {
+	return status.FromProto(ToStatus(e))
+}
+
 func (e *Error) WithData(data interface{}) *Error {
 	e.Data = data
 	return e
 }
 
+// WithDetails attaches details to the error, reported as google.protobuf.Any
+// in the details of both the default and the google.rpc.Status encodings.
+func (e *Error) WithDetails(details ...*anypb.Any) *Error {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
 func (e *Error) WithInternalMessage(internalMessage string) *Error {
 	e.InternalMessage = internalMessage
 	return e
@@ -38,6 +120,14 @@ func New(message string) *Error {
 	}
 }
 
+// NewCode returns an error reported with the HTTP status of the grpc code.
+func NewCode(c codes.Code, message string) *Error {
+	return &Error{
+		Message: message,
+		Code:    c,
+	}
+}
+
 var (
 	ErrGeneralBadRequest                    = New("general error, Bad Request")
 	ErrGeneralUnauthorized                  = New("general error, Unauthorized")
@@ -126,6 +216,12 @@ var ErrorMap = map[int]error{
 }
 
 func ParseErr(err error) (int, error) {
+	potErr := &Error{}
+	isPotErr := errors.As(err, &potErr)
+	if isPotErr && potErr.Code != codes.OK {
+		return HTTPStatusFromCode(potErr.Code), err
+	}
+
 	switch {
 	case errors.Is(err, ErrGeneralBadRequest):
 		return http.StatusBadRequest, err
@@ -208,6 +304,13 @@ func ParseErr(err error) (int, error) {
 	case errors.Is(err, ErrGeneralNetworkAuthenticationRequired):
 		return http.StatusNetworkAuthenticationRequired, err
 	default:
+		// an *Error without code reports its grpc status from ParseErr.
+		if isPotErr {
+			return http.StatusInternalServerError, err
+		}
+		if s, ok := status.FromError(err); ok {
+			return HTTPStatusFromCode(s.Code()), err
+		}
 		return http.StatusInternalServerError, err
 	}
 }
@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "general error", err: ErrGeneralNotFound, want: http.StatusNotFound},
+		{name: "wrapped general error", err: fmt.Errorf("get book, %w", ErrGeneralForbidden), want: http.StatusForbidden},
+		{name: "error without code", err: New("boom"), want: http.StatusInternalServerError},
+		{name: "grpc status error", err: status.Error(codes.Unavailable, "try later"), want: http.StatusServiceUnavailable},
+		{name: "plain error", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseErr(tt.err)
+			if got != tt.want {
+				t.Fatalf("got status %d, want %d", got, tt.want)
+			}
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestErrorError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+	}{
+		{name: "message", err: New("book not found"), want: "book not found"},
+		{name: "internal message", err: New("book not found").WithInternalMessage("row 42 missing"), want: "book not found: row 42 missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
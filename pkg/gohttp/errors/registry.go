@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+var registry = struct {
+	sync.RWMutex
+	errors map[string]*Error
+}{
+	errors: make(map[string]*Error),
+}
+
+// Register declares a domain error identified by a stable reason such as
+// USER_NOT_FOUND, returning its sentinel. Errors decoded by the generated
+// clients resolve to the registered reasons, so that errors.Is matches the
+// sentinel on both sides of the wire:
+//
+//	var ErrUserNotFound = errors.Register("USER_NOT_FOUND", codes.NotFound, "user not found")
+//
+// Register panics if the reason is already registered.
+func Register(reason string, c codes.Code, message string) *Error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.errors[reason]; ok {
+		panic(fmt.Sprintf("errors: reason %q is already registered", reason))
+	}
+
+	e := &Error{
+		Message: message,
+		Reason:  reason,
+		Code:    c,
+	}
+	registry.errors[reason] = e
+
+	return e
+}
+
+// Lookup returns the error registered with the reason.
+func Lookup(reason string) (*Error, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	e, ok := registry.errors[reason]
+	return e, ok
+}
+
+// Resolve fills the code and the message of an error decoded from a response
+// with the ones of its registered reason, when it did not carry them.
+func Resolve(e *Error) *Error {
+	registered, ok := Lookup(e.Reason)
+	if e.Reason == "" || !ok {
+		return e
+	}
+
+	if e.Code == codes.OK {
+		e.Code = registered.Code
+	}
+	if e.Message == "" {
+		e.Message = registered.Message
+	}
+
+	return e
+}
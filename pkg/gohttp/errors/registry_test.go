@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var errBookNotFound = Register("TEST_BOOK_NOT_FOUND", codes.NotFound, "book not found")
+
+func TestRegister(t *testing.T) {
+	if got, ok := Lookup("TEST_BOOK_NOT_FOUND"); !ok || got != errBookNotFound {
+		t.Fatalf("got %v, want the registered error", got)
+	}
+	if _, ok := Lookup("TEST_UNKNOWN"); ok {
+		t.Fatal("got an error for an unknown reason")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("got no panic registering a reason twice")
+		}
+	}()
+	Register("TEST_BOOK_NOT_FOUND", codes.NotFound, "book not found")
+}
+
+func TestRegisteredErrorOverTheWire(t *testing.T) {
+	sent := fmt.Errorf("get book 1, %w", errBookNotFound)
+
+	body, err := json.Marshal(errBookNotFound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := protojson.Marshal(ToStatus(sent))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "error body", body: string(body)},
+		{name: "status body", body: string(status)},
+		{name: "reason only", body: `{"reason":"TEST_BOOK_NOT_FOUND"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(Error)
+			if err := json.Unmarshal([]byte(tt.body), got); err != nil {
+				t.Fatal(err)
+			}
+			got = Resolve(got)
+
+			if !errors.Is(fmt.Errorf("client, %w", got), errBookNotFound) {
+				t.Fatalf("got %+v from %s, want %v", got, tt.body, errBookNotFound)
+			}
+			if got.Code != codes.NotFound || got.Message != "book not found" {
+				t.Fatalf("got code %v and message %q", got.Code, got.Message)
+			}
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	other := &Error{Message: "book not found", Reason: "TEST_OTHER"}
+
+	if errors.Is(other, errBookNotFound) {
+		t.Fatal("errors with different reasons match")
+	}
+	if errors.Is(New("book not found"), New("book not found")) {
+		t.Fatal("errors without reason match")
+	}
+}
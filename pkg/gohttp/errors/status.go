@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ToStatus converts err into a google.rpc.Status. The code is the one of an
+// *Error or a grpc status error, and otherwise follows the HTTP status picked
+// by ParseErr. Only the public message of an *Error is exposed, together with
+// its details.
+func ToStatus(err error) *status.Status {
+	statusCode, err := ParseErr(err)
+
+	potErr := &Error{}
+	if errors.As(err, &potErr) {
+		c := potErr.Code
+		if c == codes.OK {
+			c = CodeFromHTTPStatus(statusCode)
+		}
+
+		s := &status.Status{
+			Code:    int32(c),
+			Message: potErr.Message,
+			Details: potErr.Details,
+		}
+
+		// the reason is reported as the ErrorInfo of the status.
+		if info := (*ErrorInfo)(nil); potErr.Reason != "" && !potErr.As(&info) {
+			a, _ := anypb.New((&ErrorInfo{Reason: potErr.Reason}).toProto())
+			s.Details = append(s.Details[:len(s.Details):len(s.Details)], a)
+		}
+
+		return s
+	}
+
+	if s, ok := grpcStatus.FromError(err); ok {
+		return s.Proto()
+	}
+
+	return &status.Status{
+		Code:    int32(CodeFromHTTPStatus(statusCode)),
+		Message: err.Error(),
+	}
+}
+
+// FromStatus converts a google.rpc.Status back into an *Error, taking the
+// reason from its ErrorInfo.
+func FromStatus(s *status.Status) *Error {
+	e := &Error{
+		Message: s.GetMessage(),
+		Code:    codes.Code(s.GetCode()),
+		Details: s.GetDetails(),
+	}
+
+	if info := (*ErrorInfo)(nil); e.As(&info) {
+		e.Reason = info.Reason
+	}
+
+	return e
+}
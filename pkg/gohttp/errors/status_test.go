@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestToStatus(t *testing.T) {
+	detail, err := anypb.New(wrapperspb.String("detail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+		wantDetails int
+	}{
+		{
+			name:        "wrapped general error",
+			err:         fmt.Errorf("no such book, %w", ErrGeneralNotFound),
+			wantCode:    codes.NotFound,
+			wantMessage: ErrGeneralNotFound.Message,
+		},
+		{
+			name:        "error with details",
+			err:         fmt.Errorf("%w", (&Error{Message: "invalid book", InternalMessage: "hidden"}).WithDetails(detail)),
+			wantCode:    codes.Internal,
+			wantMessage: "invalid book",
+			wantDetails: 1,
+		},
+		{
+			name:        "error with a code",
+			err:         fmt.Errorf("%w", NewCode(codes.AlreadyExists, "book exists")),
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "book exists",
+		},
+		{
+			name:        "grpc status error",
+			err:         fmt.Errorf("lookup, %w", grpcStatus.Error(codes.Unavailable, "try later")),
+			wantCode:    codes.Unavailable,
+			wantMessage: "lookup, rpc error: code = Unavailable desc = try later",
+		},
+		{
+			name:        "plain error",
+			err:         errors.New("boom"),
+			wantCode:    codes.Internal,
+			wantMessage: "boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ToStatus(tt.err)
+			if codes.Code(s.GetCode()) != tt.wantCode || s.GetMessage() != tt.wantMessage || len(s.GetDetails()) != tt.wantDetails {
+				t.Fatalf("got %v, want code %v, message %q and %d details", s, tt.wantCode, tt.wantMessage, tt.wantDetails)
+			}
+
+			// the client rebuilds the public part of the error from the status.
+			got := FromStatus(s)
+			if got.Code != tt.wantCode || got.Message != tt.wantMessage || len(got.Details) != tt.wantDetails {
+				t.Fatalf("got %+v from %v", got, s)
+			}
+			for i, d := range got.Details {
+				if !proto.Equal(d, s.GetDetails()[i]) {
+					t.Fatalf("got detail %v, want %v", d, s.GetDetails()[i])
+				}
+			}
+		})
+	}
+}
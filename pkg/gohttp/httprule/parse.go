@@ -0,0 +1,257 @@
+package httprule
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	wildcard      = "*"
+	deepWildcard  = "**"
+	verbDelimiter = ":"
+
+	routeParamPrefix  = "p"
+	deepWildcardParam = "*"
+)
+
+// Parse parses a google.api.http path template.
+func Parse(template string) (*Template, error) {
+	p := &parser{template: template}
+	t, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("httprule: invalid path template %q: %w", template, err)
+	}
+
+	return t, nil
+}
+
+// MustParse is like Parse but panics if the template cannot be parsed.
+func MustParse(template string) *Template {
+	t, err := Parse(template)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+type parser struct {
+	template string
+	tokens   []string
+	pos      int
+
+	segments  []Segment
+	variables []Variable
+}
+
+func (p *parser) parse() (*Template, error) {
+	if !strings.HasPrefix(p.template, "/") {
+		return nil, fmt.Errorf("template must start with /")
+	}
+
+	path, verb, err := splitVerb(p.template[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	p.tokens = tokenize(path)
+	if err := p.parseSegments(false); err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	for i, seg := range p.segments {
+		if seg.Kind == SegmentDeepWildcard && i != len(p.segments)-1 {
+			return nil, fmt.Errorf("%s must be the last segment", deepWildcard)
+		}
+	}
+
+	seen := make(map[string]bool, len(p.variables))
+	for _, v := range p.variables {
+		if seen[v.FieldPath] {
+			return nil, fmt.Errorf("variable %q is bound more than once", v.FieldPath)
+		}
+		seen[v.FieldPath] = true
+	}
+
+	return &Template{
+		Template:  p.template,
+		Segments:  p.segments,
+		Verb:      verb,
+		Variables: p.variables,
+	}, nil
+}
+
+// parseSegments parses "/"-separated segments up to the end of the template,
+// or up to the closing brace when parsing the segments of a variable.
+func (p *parser) parseSegments(inVariable bool) error {
+	for {
+		if err := p.parseSegment(inVariable); err != nil {
+			return err
+		}
+
+		if p.peek() != "/" {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseSegment(inVariable bool) error {
+	tok := p.next()
+	switch tok {
+	case "":
+		return fmt.Errorf("unexpected end of template")
+	case wildcard:
+		p.segments = append(p.segments, Segment{Kind: SegmentWildcard})
+	case deepWildcard:
+		p.segments = append(p.segments, Segment{Kind: SegmentDeepWildcard})
+	case "{":
+		if inVariable {
+			return fmt.Errorf("nested variables are not allowed")
+		}
+		return p.parseVariable()
+	case "/", "}", "=":
+		return fmt.Errorf("unexpected %q", tok)
+	default:
+		if strings.ContainsAny(tok, "*{}=") {
+			return fmt.Errorf("invalid literal %q", tok)
+		}
+		p.segments = append(p.segments, Segment{Kind: SegmentLiteral, Literal: tok})
+	}
+
+	return nil
+}
+
+func (p *parser) parseVariable() error {
+	fieldPath := p.next()
+	if err := validateFieldPath(fieldPath); err != nil {
+		return err
+	}
+
+	start := len(p.segments)
+	switch p.next() {
+	case "}":
+		p.segments = append(p.segments, Segment{Kind: SegmentWildcard})
+	case "=":
+		if err := p.parseSegments(true); err != nil {
+			return err
+		}
+		if tok := p.next(); tok != "}" {
+			return fmt.Errorf("variable %q: expected }, got %q", fieldPath, tok)
+		}
+	default:
+		return fmt.Errorf("variable %q: expected } or =", fieldPath)
+	}
+
+	p.variables = append(p.variables, Variable{
+		FieldPath: fieldPath,
+		Start:     start,
+		End:       len(p.segments),
+	})
+
+	return nil
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.pos++
+	}
+
+	return tok
+}
+
+// splitVerb splits the trailing ":verb" from the path, ignoring colons inside
+// variables and before the last segment.
+func splitVerb(path string) (string, string, error) {
+	depth := 0
+	verbAt := -1
+	for i, c := range path {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				verbAt = -1
+			}
+		case ':':
+			if depth == 0 {
+				verbAt = i
+			}
+		}
+	}
+
+	if depth != 0 {
+		return "", "", fmt.Errorf("unbalanced braces")
+	}
+
+	if verbAt < 0 {
+		return path, "", nil
+	}
+
+	verb := path[verbAt+1:]
+	if verb == "" || strings.ContainsAny(verb, "/{}*=") {
+		return "", "", fmt.Errorf("invalid verb %q", verb)
+	}
+
+	return path[:verbAt], verb, nil
+}
+
+// tokenize splits a path into literals and the "/", "{", "}" and "=" symbols.
+func tokenize(path string) []string {
+	var (
+		tokens []string
+		start  int
+	)
+
+	for i, c := range path {
+		switch c {
+		case '/', '{', '}', '=':
+			if i > start {
+				tokens = append(tokens, path[start:i])
+			}
+			tokens = append(tokens, string(c))
+			start = i + 1
+		}
+	}
+
+	if start < len(path) {
+		tokens = append(tokens, path[start:])
+	}
+
+	return tokens
+}
+
+func validateFieldPath(fieldPath string) error {
+	if fieldPath == "" {
+		return fmt.Errorf("empty variable name")
+	}
+
+	for _, ident := range strings.Split(fieldPath, ".") {
+		if ident == "" {
+			return fmt.Errorf("invalid field path %q", fieldPath)
+		}
+
+		for i, c := range ident {
+			isLetter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+			if !isLetter && (i == 0 || c < '0' || c > '9') {
+				return fmt.Errorf("invalid field path %q", fieldPath)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+// Package httprule parses google.api.http path templates and maps them onto
+// router patterns, request paths and field values.
+//
+// The template syntax is the one documented in google/api/http.proto:
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+package httprule
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type SegmentKind int
+
+const (
+	SegmentLiteral SegmentKind = iota
+	SegmentWildcard
+	SegmentDeepWildcard
+)
+
+// Segment is a single path segment of a template.
+type Segment struct {
+	Kind    SegmentKind
+	Literal string
+}
+
+// Variable binds the segments [Start, End) of a template to a field path.
+type Variable struct {
+	FieldPath string
+	Start     int
+	End       int
+}
+
+// Template is a parsed google.api.http path template.
+type Template struct {
+	Template  string
+	Segments  []Segment
+	Verb      string
+	Variables []Variable
+}
+
+// FieldPaths returns the field paths bound by the template variables.
+func (t *Template) FieldPaths() []string {
+	paths := make([]string, 0, len(t.Variables))
+	for _, v := range t.Variables {
+		paths = append(paths, v.FieldPath)
+	}
+
+	return paths
+}
+
+// RoutePattern returns the chi routing pattern of the template. Every "*"
+// segment becomes a named parameter and a trailing "**" becomes the catch-all
+// "*" parameter, so literals keep routes distinct and variables are rebuilt
+// by Extract from the router parameters. Templates differing only by the verb
+// following a "**" segment share a pattern, Extract telling them apart.
+func (t *Template) RoutePattern() string {
+	var b strings.Builder
+	for i, seg := range t.Segments {
+		b.WriteString("/")
+		switch seg.Kind {
+		case SegmentLiteral:
+			b.WriteString(seg.Literal)
+		case SegmentWildcard:
+			b.WriteString("{" + routeParam(i) + "}")
+		case SegmentDeepWildcard:
+			// a catch-all parameter has to end the pattern, the verb is
+			// checked by Extract instead.
+			b.WriteString(deepWildcardParam)
+			return b.String()
+		}
+	}
+
+	if t.Verb != "" {
+		b.WriteString(verbDelimiter + t.Verb)
+	}
+
+	return b.String()
+}
+
+// Extract rebuilds the template variables from the router parameters of a
+// request matched by RoutePattern. It reports false when the request does not
+// match the template.
+func (t *Template) Extract(param func(name string) string) (map[string]string, bool) {
+	segments := make([]string, len(t.Segments))
+	for i, seg := range t.Segments {
+		switch seg.Kind {
+		case SegmentLiteral:
+			segments[i] = seg.Literal
+		case SegmentWildcard:
+			segments[i] = param(routeParam(i))
+		case SegmentDeepWildcard:
+			rest := param(deepWildcardParam)
+			if t.Verb != "" {
+				var ok bool
+				rest, ok = strings.CutSuffix(rest, verbDelimiter+t.Verb)
+				if !ok {
+					return nil, false
+				}
+			}
+			segments[i] = rest
+		}
+	}
+
+	vars := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		vars[v.FieldPath] = strings.Join(segments[v.Start:v.End], "/")
+	}
+
+	return vars, true
+}
+
+// Expand builds the escaped request path of the template from the variable
+// values, checking each value against the segments of its variable.
+func (t *Template) Expand(vars map[string]string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(t.Segments); i++ {
+		v, ok := t.variableAt(i)
+		if !ok {
+			seg := t.Segments[i]
+			if seg.Kind != SegmentLiteral {
+				return "", fmt.Errorf("httprule: cannot expand unnamed wildcard in %s", t.Template)
+			}
+			b.WriteString("/" + seg.Literal)
+			continue
+		}
+
+		val := vars[v.FieldPath]
+		if val == "" {
+			return "", fmt.Errorf("httprule: missing value for path variable %q in %s", v.FieldPath, t.Template)
+		}
+
+		expanded, err := expandVariable(t.Segments[v.Start:v.End], val)
+		if err != nil {
+			return "", fmt.Errorf("httprule: path variable %q in %s: %w", v.FieldPath, t.Template, err)
+		}
+		b.WriteString(expanded)
+		i = v.End - 1
+	}
+
+	if t.Verb != "" {
+		b.WriteString(verbDelimiter + t.Verb)
+	}
+
+	return b.String(), nil
+}
+
+func (t *Template) String() string {
+	return t.Template
+}
+
+// expandVariable escapes val as the segments of a variable, a single "*"
+// segment accepting any value and longer patterns requiring val to match.
+func expandVariable(segments []Segment, val string) (string, error) {
+	if len(segments) == 1 && segments[0].Kind == SegmentWildcard {
+		return "/" + url.PathEscape(val), nil
+	}
+
+	parts := strings.Split(val, "/")
+	var b strings.Builder
+	for i, seg := range segments {
+		if seg.Kind == SegmentDeepWildcard && i <= len(parts) {
+			for _, part := range parts[i:] {
+				b.WriteString("/" + url.PathEscape(part))
+			}
+			return b.String(), nil
+		}
+
+		if i >= len(parts) || parts[i] == "" || (seg.Kind == SegmentLiteral && parts[i] != seg.Literal) {
+			return "", fmt.Errorf("value %q does not match %s", val, segmentsString(segments))
+		}
+		b.WriteString("/" + url.PathEscape(parts[i]))
+	}
+
+	if len(parts) != len(segments) {
+		return "", fmt.Errorf("value %q does not match %s", val, segmentsString(segments))
+	}
+
+	return b.String(), nil
+}
+
+// variableAt returns the variable starting at segment i.
+func (t *Template) variableAt(i int) (Variable, bool) {
+	for _, v := range t.Variables {
+		if v.Start == i {
+			return v, true
+		}
+	}
+
+	return Variable{}, false
+}
+
+func segmentsString(segments []Segment) string {
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		switch seg.Kind {
+		case SegmentLiteral:
+			parts = append(parts, seg.Literal)
+		case SegmentWildcard:
+			parts = append(parts, wildcard)
+		case SegmentDeepWildcard:
+			parts = append(parts, deepWildcard)
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func routeParam(i int) string {
+	return fmt.Sprintf("%s%d", routeParamPrefix, i)
+}
@@ -0,0 +1,166 @@
+package httprule
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		template string
+		want     *Template
+		wantErr  string
+	}{
+		{
+			template: "/v1/users",
+			want: &Template{
+				Segments: []Segment{{Kind: SegmentLiteral, Literal: "v1"}, {Kind: SegmentLiteral, Literal: "users"}},
+			},
+		},
+		{
+			template: "/v1/users/{user.id}",
+			want: &Template{
+				Segments:  []Segment{{Kind: SegmentLiteral, Literal: "v1"}, {Kind: SegmentLiteral, Literal: "users"}, {Kind: SegmentWildcard}},
+				Variables: []Variable{{FieldPath: "user.id", Start: 2, End: 3}},
+			},
+		},
+		{
+			template: "/v1/{name=shelves/*/books/*}:publish",
+			want: &Template{
+				Segments: []Segment{
+					{Kind: SegmentLiteral, Literal: "v1"},
+					{Kind: SegmentLiteral, Literal: "shelves"},
+					{Kind: SegmentWildcard},
+					{Kind: SegmentLiteral, Literal: "books"},
+					{Kind: SegmentWildcard},
+				},
+				Verb:      "publish",
+				Variables: []Variable{{FieldPath: "name", Start: 1, End: 5}},
+			},
+		},
+		{
+			template: "/v1/files/{name=**}",
+			want: &Template{
+				Segments:  []Segment{{Kind: SegmentLiteral, Literal: "v1"}, {Kind: SegmentLiteral, Literal: "files"}, {Kind: SegmentDeepWildcard}},
+				Variables: []Variable{{FieldPath: "name", Start: 2, End: 3}},
+			},
+		},
+		{
+			template: "/v1/*/things:list",
+			want: &Template{
+				Segments: []Segment{{Kind: SegmentLiteral, Literal: "v1"}, {Kind: SegmentWildcard}, {Kind: SegmentLiteral, Literal: "things"}},
+				Verb:     "list",
+			},
+		},
+		{template: "v1/users", wantErr: "must start with /"},
+		{template: "/v1/{name", wantErr: "unbalanced braces"},
+		{template: "/v1/{a={b}}", wantErr: "nested variables"},
+		{template: "/v1/{name=**}/x", wantErr: "must be the last segment"},
+		{template: "/v1/{id}/{id}", wantErr: "bound more than once"},
+		{template: "/v1/{1id}", wantErr: "invalid field path"},
+		{template: "/v1/users:", wantErr: "invalid verb"},
+		{template: "/v1//users", wantErr: "unexpected"},
+		{template: "/v1/us*ers", wantErr: "invalid literal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Parse(tt.template)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.Template = tt.template
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoutePattern(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: "/v1/users", want: "/v1/users"},
+		{template: "/v1/users/{id}", want: "/v1/users/{p2}"},
+		{template: "/v1/{name=shelves/*/books/*}", want: "/v1/shelves/{p2}/books/{p4}"},
+		{template: "/v1/users/{id}:activate", want: "/v1/users/{p2}:activate"},
+		{template: "/v1/files/{name=**}", want: "/v1/files/*"},
+		{template: "/v1/files/{name=**}:download", want: "/v1/files/*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if got := MustParse(tt.template).RoutePattern(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		template string
+		params   map[string]string
+		want     map[string]string
+		wantOK   bool
+	}{
+		{template: "/v1/users", want: map[string]string{}, wantOK: true},
+		{template: "/v1/users/{user.id}", params: map[string]string{"p2": "42"}, want: map[string]string{"user.id": "42"}, wantOK: true},
+		{template: "/v1/{name=shelves/*/books/*}", params: map[string]string{"p2": "a", "p4": "b"}, want: map[string]string{"name": "shelves/a/books/b"}, wantOK: true},
+		{template: "/v1/files/{name=**}", params: map[string]string{"*": "a/b.txt"}, want: map[string]string{"name": "a/b.txt"}, wantOK: true},
+		{template: "/v1/files/{name=**}:download", params: map[string]string{"*": "a/b.txt:download"}, want: map[string]string{"name": "a/b.txt"}, wantOK: true},
+		{template: "/v1/files/{name=**}:download", params: map[string]string{"*": "a/b.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, ok := MustParse(tt.template).Extract(func(name string) string { return tt.params[name] })
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		template string
+		vars     map[string]string
+		want     string
+		wantErr  string
+	}{
+		{template: "/v1/users", want: "/v1/users"},
+		{template: "/v1/users/{id}", vars: map[string]string{"id": "a b/c"}, want: "/v1/users/a%20b%2Fc"},
+		{template: "/v1/users/{id}:activate", vars: map[string]string{"id": "42"}, want: "/v1/users/42:activate"},
+		{template: "/v1/{name=shelves/*/books/*}", vars: map[string]string{"name": "shelves/a/books/b"}, want: "/v1/shelves/a/books/b"},
+		{template: "/v1/{name=shelves/*/books/*}", vars: map[string]string{"name": "shelves/a"}, wantErr: "does not match"},
+		{template: "/v1/{name=shelves/*/books/*}", vars: map[string]string{"name": "racks/a/books/b"}, wantErr: "does not match"},
+		{template: "/v1/files/{name=**}", vars: map[string]string{"name": "a/b c.txt"}, want: "/v1/files/a/b%20c.txt"},
+		{template: "/v1/users/{id}", wantErr: "missing value"},
+		{template: "/v1/*/things", wantErr: "unnamed wildcard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := MustParse(tt.template).Expand(tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,37 @@
 package option
 
+import (
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
 type BinderOptions struct {
-	Headers     map[string]any
-	ContentType ContentType
-	Operation   string
-	RequestID   string
+	Headers      map[string]any
+	ContentType  ContentType
+	Operation    string
+	RequestID    string
+	PathTemplate string
+	Body         string
+	ResponseBody string
+	DialContext  DialContextFunc
+	Dialer       *websocket.Dialer
+	RetryPolicy  *RetryPolicy
+
+	JSONMarshalOptions   *protojson.MarshalOptions
+	JSONUnmarshalOptions *protojson.UnmarshalOptions
+
+	MaxFormMemory int64
+	MaxFormSize   int64
 }
 
 type BinderOption func(*BinderOptions)
 
 func NewBinderOptions(options ...BinderOption) *BinderOptions {
 	o := BinderOptions{
-		Headers:     make(map[string]any),
-		ContentType: ContentTypeApplicationJson,
+		Headers:       make(map[string]any),
+		ContentType:   ContentTypeApplicationJson,
+		MaxFormMemory: DefaultMaxFormMemory,
+		MaxFormSize:   DefaultMaxFormSize,
 	}
 
 	for _, option := range options {
@@ -51,3 +70,71 @@ func WithRequestID(requestID string) BinderOption {
 		o.RequestID = requestID
 	}
 }
+
+func WithPathTemplate(pathTemplate string) BinderOption {
+	return func(o *BinderOptions) {
+		o.PathTemplate = pathTemplate
+	}
+}
+
+func WithBody(body string) BinderOption {
+	return func(o *BinderOptions) {
+		o.Body = body
+	}
+}
+
+func WithResponseBody(responseBody string) BinderOption {
+	return func(o *BinderOptions) {
+		o.ResponseBody = responseBody
+	}
+}
+
+// WithDialContext sets how the websocket of a streaming method is dialed, the
+// network by default.
+func WithDialContext(dialContext DialContextFunc) BinderOption {
+	return func(o *BinderOptions) {
+		o.DialContext = dialContext
+	}
+}
+
+// WithDialer dials the websocket of a streaming method with a copy of dialer,
+// websocket.DefaultDialer by default.
+func WithDialer(dialer *websocket.Dialer) BinderOption {
+	return func(o *BinderOptions) {
+		o.Dialer = dialer
+	}
+}
+
+// WithJSONMarshalOptions encodes JSON bodies with opts instead of the options
+// of the registered JSON codec.
+func WithJSONMarshalOptions(opts protojson.MarshalOptions) BinderOption {
+	return func(o *BinderOptions) {
+		o.JSONMarshalOptions = &opts
+	}
+}
+
+// WithJSONUnmarshalOptions decodes JSON bodies with opts instead of the options
+// of the registered JSON codec.
+func WithJSONUnmarshalOptions(opts protojson.UnmarshalOptions) BinderOption {
+	return func(o *BinderOptions) {
+		o.JSONUnmarshalOptions = &opts
+	}
+}
+
+// WithMaxFormMemory sets the size of the multipart form parts decoded in
+// memory, DefaultMaxFormMemory by default. Larger files are stored in temporary
+// files while the request is decoded.
+func WithMaxFormMemory(maxMemory int64) BinderOption {
+	return func(o *BinderOptions) {
+		o.MaxFormMemory = maxMemory
+	}
+}
+
+// WithMaxFormSize rejects the form bodies larger than maxSize with 413 Request
+// Entity Too Large, DefaultMaxFormSize by default. A negative maxSize lifts the
+// limit.
+func WithMaxFormSize(maxSize int64) BinderOption {
+	return func(o *BinderOptions) {
+		o.MaxFormSize = maxSize
+	}
+}
@@ -1,10 +1,41 @@
 package option
 
-import "time"
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/transport"
+	"github.com/gorilla/websocket"
+)
+
+// DialContextFunc opens the connections of the websockets of a client.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Middleware wraps the http.RoundTripper of a client, such as to add headers,
+// metrics or tracing to every request.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an http.RoundTripper calling a function, convenient to
+// write a Middleware.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
 
 type ClientOptions struct {
-	BaseURL string
-	Timeout time.Duration
+	BaseURL     string
+	Timeout     time.Duration
+	HTTPClient  *http.Client
+	Transport   http.RoundTripper
+	Middlewares []Middleware
+	RetryPolicy *RetryPolicy
+	DialContext DialContextFunc
+	CallOptions []BinderOption
+
+	WebSocketDialer *websocket.Dialer
 }
 
 type ClientOption func(*ClientOptions)
@@ -22,6 +53,71 @@ func NewClientOptions(options ...ClientOption) *ClientOptions {
 	return &o
 }
 
+// NewHTTPClient returns the http.Client sending the requests of a generated
+// client: a copy of the client set with WithHTTPClient, or a client with the
+// timeout of the options, whose transport is replaced by the one set with
+// WithTransport, retries requests following the retry policy and is wrapped
+// by the middlewares, which see each call once whatever its attempts.
+func (o *ClientOptions) NewHTTPClient() *http.Client {
+	client := &http.Client{Timeout: o.Timeout}
+	if o.HTTPClient != nil {
+		c := *o.HTTPClient
+		client = &c
+	}
+
+	if o.Transport != nil {
+		client.Transport = o.Transport
+	}
+
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	// a call may set a retry policy even when the client has none.
+	rt = &retryTransport{next: rt, policy: o.RetryPolicy}
+	for i := len(o.Middlewares) - 1; i >= 0; i-- {
+		rt = o.Middlewares[i](rt)
+	}
+	client.Transport = rt
+
+	return client
+}
+
+// NewWebSocketDialer returns the dialer of the websockets of a generated
+// client: a copy of the dialer set with WithWebSocketDialer, or of
+// websocket.DefaultDialer using the TLS config, proxy and dial function of the
+// client transport when it is an *http.Transport. Other transports are not
+// used by websockets.
+func (o *ClientOptions) NewWebSocketDialer() *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	if o.WebSocketDialer != nil {
+		dialer = *o.WebSocketDialer
+	} else if t, ok := o.transport().(*http.Transport); ok {
+		dialer.TLSClientConfig = t.TLSClientConfig
+		dialer.Proxy = t.Proxy
+		dialer.NetDialContext = t.DialContext
+	}
+
+	if o.DialContext != nil {
+		dialer.NetDialContext = o.DialContext
+	}
+
+	return &dialer
+}
+
+// transport returns the transport the requests are sent with, before the
+// retries and middlewares.
+func (o *ClientOptions) transport() http.RoundTripper {
+	switch {
+	case o.Transport != nil:
+		return o.Transport
+	case o.HTTPClient != nil && o.HTTPClient.Transport != nil:
+		return o.HTTPClient.Transport
+	default:
+		return http.DefaultTransport
+	}
+}
+
 func WithBaseURL(baseURL string) ClientOption {
 	return func(o *ClientOptions) {
 		o.BaseURL = baseURL
@@ -33,3 +129,61 @@ func WithTimeout(timeout time.Duration) ClientOption {
 		o.Timeout = timeout
 	}
 }
+
+// WithHTTPClient sends the requests with a copy of client, configured with
+// its own timeout, redirect policy and cookie jar.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *ClientOptions) {
+		o.HTTPClient = client
+	}
+}
+
+// WithTransport sends the requests with roundTripper, such as an *http.Transport
+// set up for mTLS, a proxy or connection pooling, instead of the transport of
+// the http.Client.
+func WithTransport(roundTripper http.RoundTripper) ClientOption {
+	return func(o *ClientOptions) {
+		o.Transport = roundTripper
+	}
+}
+
+// WithWebSocketDialer dials the websockets of the client- and bidi-streaming
+// methods with a copy of dialer instead of a dialer derived from the transport.
+func WithWebSocketDialer(dialer *websocket.Dialer) ClientOption {
+	return func(o *ClientOptions) {
+		o.WebSocketDialer = dialer
+	}
+}
+
+// WithMiddleware wraps the transport of the client with middlewares, the first
+// one being the outermost.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(o *ClientOptions) {
+		o.Middlewares = append(o.Middlewares, middlewares...)
+	}
+}
+
+// WithCallOptions applies opts to every call of the client, before the
+// options of the call itself:
+//
+//	option.WithCallOptions(option.WithJSONMarshalOptions(protojson.MarshalOptions{UseProtoNames: true}))
+func WithCallOptions(opts ...BinderOption) ClientOption {
+	return func(o *ClientOptions) {
+		o.CallOptions = append(o.CallOptions, opts...)
+	}
+}
+
+// WithHandler sends the requests of the client to handler in memory, such as
+// the handler returned by a generated Register function, instead of over the
+// network. The base URL defaults to http://in-process and must use the http
+// scheme.
+func WithHandler(handler http.Handler) ClientOption {
+	return func(o *ClientOptions) {
+		t := transport.NewHandlerTransport(handler)
+		o.Transport = t
+		o.DialContext = t.DialContext
+		if o.BaseURL == "" {
+			o.BaseURL = "http://" + transport.Host
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package option
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNewWebSocketDialer(t *testing.T) {
+	tlsConfig := &tls.Config{ServerName: "api.example.com"}
+	dialer := &websocket.Dialer{HandshakeTimeout: time.Second}
+
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		wantTLS     *tls.Config
+		wantProxy   bool
+		wantTimeout time.Duration
+	}{
+		{name: "default", wantProxy: true, wantTimeout: websocket.DefaultDialer.HandshakeTimeout},
+		{name: "transport", opts: []ClientOption{WithTransport(&http.Transport{TLSClientConfig: tlsConfig})}, wantTLS: tlsConfig, wantTimeout: websocket.DefaultDialer.HandshakeTimeout},
+		{name: "http client", opts: []ClientOption{WithHTTPClient(&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}})}, wantTLS: tlsConfig, wantTimeout: websocket.DefaultDialer.HandshakeTimeout},
+		{name: "dialer", opts: []ClientOption{WithTransport(&http.Transport{TLSClientConfig: tlsConfig}), WithWebSocketDialer(dialer)}, wantTimeout: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewClientOptions(tt.opts...).NewWebSocketDialer()
+			if got.TLSClientConfig != tt.wantTLS {
+				t.Fatalf("got TLS config %v, want %v", got.TLSClientConfig, tt.wantTLS)
+			}
+			if (got.Proxy != nil) != tt.wantProxy {
+				t.Fatalf("got proxy %v, want %v", got.Proxy != nil, tt.wantProxy)
+			}
+			if got == dialer || got.HandshakeTimeout != tt.wantTimeout {
+				t.Fatalf("got handshake timeout %v, want %v", got.HandshakeTimeout, tt.wantTimeout)
+			}
+		})
+	}
+}
@@ -6,11 +6,25 @@ type (
 
 const (
 	ContentTypeApplicationJson ContentType = "application/json"
+	ContentTypeEventStream     ContentType = "text/event-stream"
+	ContentTypeNDJSON          ContentType = "application/x-ndjson"
+	ContentTypeProtobuf        ContentType = "application/x-protobuf"
+	ContentTypeProto           ContentType = "application/proto"
+	ContentTypeFormURLEncoded  ContentType = "application/x-www-form-urlencoded"
+	ContentTypeMultipartForm   ContentType = "multipart/form-data"
 
 	ContentTypeHeader   = "Content-Type"
+	AcceptHeader        = "Accept"
 	AuthorizationHeader = "Authorization"
 	UserAgentHeader     = "User-Agent"
 	XRequestIDHeader    = "X-Request-ID"
+	RetryAfterHeader    = "Retry-After"
+
+	// DefaultMaxFormMemory is the size of the multipart form parts kept in
+	// memory, the rest of the files being stored in temporary files.
+	DefaultMaxFormMemory int64 = 32 << 20
+	// DefaultMaxFormSize is the size of the largest form body accepted.
+	DefaultMaxFormSize int64 = 10 << 20
 )
 
 func (c ContentType) String() string {
@@ -0,0 +1,227 @@
+package option
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how the requests of a client are retried. A request
+// is retried when it fails before a response or when the response status is
+// retryable, after an exponential backoff or the delay of the Retry-After
+// header of the response.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one, retries
+	// being disabled below 2.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, 100ms when zero.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff, 5s when zero. A response whose Retry-After
+	// delay exceeds it is returned instead of being retried.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each retry, 2 when zero.
+	Multiplier float64
+	// Jitter randomizes each backoff by up to this fraction of it, in [0, 1].
+	Jitter float64
+	// RetryableStatusCodes are the retried response statuses, 429, 502, 503
+	// and 504 when nil.
+	RetryableStatusCodes []int
+	// RetryNonIdempotent retries requests of any method, instead of only GET,
+	// HEAD, OPTIONS, TRACE, PUT and DELETE requests.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy makes up to 3 attempts of idempotent requests.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// WithRetryPolicy retries the requests of the client following policy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *ClientOptions) {
+		o.RetryPolicy = &policy
+	}
+}
+
+// WithCallRetryPolicy overrides the retry policy of the client for a single
+// call, RetryPolicy{} disabling its retries.
+func WithCallRetryPolicy(policy RetryPolicy) BinderOption {
+	return func(o *BinderOptions) {
+		o.RetryPolicy = &policy
+	}
+}
+
+type binderOptionsKey struct{}
+
+// NewBinderContext returns a copy of ctx carrying the options of a call, for
+// the transport and the middlewares of the client to read.
+func NewBinderContext(ctx context.Context, opts *BinderOptions) context.Context {
+	return context.WithValue(ctx, binderOptionsKey{}, opts)
+}
+
+// BinderOptionsFromContext returns the options of the call carried by ctx.
+func BinderOptionsFromContext(ctx context.Context) (*BinderOptions, bool) {
+	opts, ok := ctx.Value(binderOptionsKey{}).(*BinderOptions)
+	return opts, ok
+}
+
+// retryTransport retries the requests sent by next following policy, or the
+// policy of the call.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy *RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.policy
+	if opts, ok := BinderOptionsFromContext(req.Context()); ok && opts.RetryPolicy != nil {
+		policy = opts.RetryPolicy
+	}
+
+	// a body that cannot be rebuilt is sent once.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if policy == nil || policy.MaxAttempts < 2 || !policy.allows(req.Method) || !replayable {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	backoff := policy.initialBackoff()
+	for attempt := 1; ; attempt++ {
+		res, err := t.next.RoundTrip(req)
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(res, err) {
+			return res, err
+		}
+
+		delay := policy.jitter(backoff)
+		if res != nil {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get(RetryAfterHeader)); ok {
+				if retryAfter > policy.maxBackoff() {
+					return res, err
+				}
+				delay = retryAfter
+			}
+		}
+
+		// the last response is returned when there is no time left to retry.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return res, err
+		}
+
+		if res != nil {
+			// drain the body so that the connection is reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff = min(time.Duration(float64(backoff)*policy.multiplier()), policy.maxBackoff())
+
+		// the request must not be modified, the next attempt sends a copy.
+		body := req.Body
+		if req.GetBody != nil {
+			if body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = req.Clone(ctx)
+		req.Body = body
+	}
+}
+
+func (p *RetryPolicy) allows(method string) bool {
+	if p.RetryNonIdempotent {
+		return true
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *RetryPolicy) retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = defaultRetryableStatusCodes
+	}
+
+	return slices.Contains(codes, res.StatusCode)
+}
+
+func (p *RetryPolicy) jitter(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return backoff
+	}
+
+	return time.Duration(float64(backoff) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
+
+func (p *RetryPolicy) initialBackoff() time.Duration {
+	if p.InitialBackoff <= 0 {
+		return DefaultRetryPolicy.InitialBackoff
+	}
+
+	return p.InitialBackoff
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return DefaultRetryPolicy.MaxBackoff
+	}
+
+	return p.MaxBackoff
+}
+
+func (p *RetryPolicy) multiplier() float64 {
+	if p.Multiplier <= 0 {
+		return DefaultRetryPolicy.Multiplier
+	}
+
+	return p.Multiplier
+}
+
+// parseRetryAfter parses a Retry-After header, a number of seconds or an HTTP
+// date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,125 @@
+package option
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statusTransport replies with the statuses in order, the last one repeating,
+// and counts the attempts.
+type statusTransport struct {
+	statuses   []int
+	retryAfter string
+	attempts   int
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		if _, err := io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	status := t.statuses[min(t.attempts, len(t.statuses)-1)]
+	t.attempts++
+
+	header := http.Header{}
+	if t.retryAfter != "" {
+		header.Set(RetryAfterHeader, t.retryAfter)
+	}
+
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func TestRetryTransport(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		policy       *RetryPolicy
+		method       string
+		body         bool
+		statuses     []int
+		retryAfter   string
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "no policy", method: http.MethodGet, statuses: []int{503}, wantStatus: 503, wantAttempts: 1},
+		{name: "success", policy: policy, method: http.MethodGet, statuses: []int{200}, wantStatus: 200, wantAttempts: 1},
+		{name: "retried", policy: policy, method: http.MethodGet, statuses: []int{503, 502, 200}, wantStatus: 200, wantAttempts: 3},
+		{name: "max attempts", policy: policy, method: http.MethodGet, statuses: []int{503}, wantStatus: 503, wantAttempts: 3},
+		{name: "not retryable", policy: policy, method: http.MethodGet, statuses: []int{500}, wantStatus: 500, wantAttempts: 1},
+		{name: "custom codes", policy: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, RetryableStatusCodes: []int{500}}, method: http.MethodGet, statuses: []int{500, 200}, wantStatus: 200, wantAttempts: 2},
+		{name: "non idempotent", policy: policy, method: http.MethodPost, statuses: []int{503}, wantStatus: 503, wantAttempts: 1},
+		{name: "non idempotent allowed", policy: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, RetryNonIdempotent: true}, method: http.MethodPost, body: true, statuses: []int{503, 200}, wantStatus: 200, wantAttempts: 2},
+		{name: "retry after", policy: policy, method: http.MethodGet, statuses: []int{429, 200}, retryAfter: "0", wantStatus: 200, wantAttempts: 2},
+		{name: "retry after exceeding max backoff", policy: policy, method: http.MethodGet, statuses: []int{429, 200}, retryAfter: "3600", wantStatus: 429, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &statusTransport{statuses: tt.statuses, retryAfter: tt.retryAfter}
+			rt := &retryTransport{next: next, policy: tt.policy}
+
+			var body io.Reader
+			if tt.body {
+				body = strings.NewReader("{}")
+			}
+			req, err := http.NewRequest(tt.method, "http://example.com", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.wantStatus || next.attempts != tt.wantAttempts {
+				t.Fatalf("got %d after %d attempts, want %d after %d", res.StatusCode, next.attempts, tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCallPolicy(t *testing.T) {
+	next := &statusTransport{statuses: []int{503}}
+	rt := &retryTransport{next: next, policy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}}
+
+	ctx := NewBinderContext(context.Background(), NewBinderOptions(WithCallRetryPolicy(RetryPolicy{})))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if next.attempts != 1 {
+		t.Fatalf("got %d attempts, want 1", next.attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: ""},
+		{value: "soon"},
+		{value: "2", want: 2 * time.Second, wantOK: true},
+		{value: "-1", want: 0, wantOK: true},
+		{value: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("got %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
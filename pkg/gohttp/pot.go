@@ -2,14 +2,18 @@ package gohttp
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
+	"unicode"
 
 	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder"
 	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -21,58 +25,92 @@ type (
 
 	MethodDescriptor struct {
 		MethodName string
+		Operation  string
 
-		HttpMethod string
-		HttpPath   string
-		Handler    MethodHandlerFunc
+		HttpMethod   string
+		HttpPath     string
+		Body         string
+		ResponseBody string
+		Handler      MethodHandlerFunc
 	}
 
 	ServiceDescriptor struct {
 		ServiceName string
 		HandlerType interface{}
 		Methods     []MethodDescriptor
+		Streams     []StreamDescriptor
 	}
 )
 
-func NewDecoderFunc(r *http.Request) DecoderFunc {
-	dec := binder.RequestDecoder{Request: r}
+func NewDecoderFunc(r *http.Request, opts ...option.BinderOption) DecoderFunc {
+	dec := binder.NewRequestDecoder(r, opts...)
 
 	return func(req interface{}) error {
 		return dec.Bind(req)
 	}
 }
 
-func httpHandlerWrapper(impl interface{}, handler MethodHandlerFunc) http.HandlerFunc {
-	type ErrResp struct {
-		Message string `json:"message"`
-	}
+func httpHandlerWrapper(impl interface{}, method MethodDescriptor, tmpl *httprule.Template, opts *ServerOptions) http.HandlerFunc {
+	middleware := opts.middleware(&UnaryServerInfo{
+		Server:    impl,
+		Operation: method.Operation,
+	})
 
 	return func(rw http.ResponseWriter, r *http.Request) {
-		decoder := NewDecoderFunc(r)
-		out, err := handler(r.Context(), impl, decoder, nil)
+		if err := bindPathVars(r, tmpl); err != nil {
+			opts.encodeError(rw, r, err)
+			return
+		}
+
+		decoder := NewDecoderFunc(r, opts.binderOptions(option.WithPathTemplate(method.HttpPath), option.WithBody(method.Body))...)
+		out, err := method.Handler(r.Context(), impl, decoder, middleware)
 		if err == nil {
-			encoder := &binder.ResponseEncoder{ResponseWriter: rw}
+			contentType := binder.NegotiateContentType(r.Header.Get(option.AcceptHeader))
+			encoder := binder.NewResponseEncoder(rw, opts.binderOptions(option.WithResponseBody(method.ResponseBody), option.WithContentType(contentType))...)
 			err = encoder.BindBody(out)
 			if err == nil {
 				return
 			}
 		}
 
-		statusCode, err := potErrors.ParseErr(err)
+		opts.encodeError(rw, r, err)
+	}
+}
 
-		potErr := &potErrors.Error{}
-		if errors.As(err, &potErr) {
-			rw.WriteHeader(statusCode)
-			json.NewEncoder(rw).Encode(err)
-			return
+// bindPathVars extracts the variables of tmpl from the route of r and exposes
+// them through r.PathValue under their field paths.
+func bindPathVars(r *http.Request, tmpl *httprule.Template) error {
+	vars, ok := tmpl.Extract(pathParam(r))
+	if !ok {
+		return fmt.Errorf("path does not match %s, %w", tmpl, potErrors.ErrGeneralNotFound)
+	}
+
+	for fieldPath, val := range vars {
+		r.SetPathValue(fieldPath, val)
+	}
+
+	return nil
+}
+
+// pathParam reads the router parameters of r, unescaping them when the router
+// matched the escaped path.
+func pathParam(r *http.Request) func(name string) string {
+	return func(name string) string {
+		val := chi.URLParam(r, name)
+		if r.URL.RawPath == "" {
+			return val
+		}
+
+		unescaped, err := url.PathUnescape(val)
+		if err != nil {
+			return val
 		}
 
-		rw.WriteHeader(statusCode)
-		json.NewEncoder(rw).Encode(ErrResp{Message: err.Error()})
+		return unescaped
 	}
 }
 
-func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router chi.Router) http.Handler {
+func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router chi.Router, opts ...ServerOption) http.Handler {
 	if impl != nil {
 		ht := reflect.TypeOf(desc.HandlerType).Elem()
 		st := reflect.TypeOf(impl)
@@ -81,27 +119,136 @@ func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router ch
 		}
 	}
 
+	options := NewServerOptions(opts...)
+
+	var rs routes
 	for _, method := range desc.Methods {
-		switch method.HttpMethod {
-		case http.MethodGet:
-			router.Get(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
-		case http.MethodPost:
-			router.Post(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
-		case http.MethodPut:
-			router.Put(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
-		case http.MethodPatch:
-			router.Patch(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
-		case http.MethodDelete:
-			router.Delete(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
-		default:
-			panic("pot: RegisterService found unsupported HTTP method: " + method.HttpMethod)
+		tmpl := parsePath(method.HttpPath)
+		rs.add(method.HttpMethod, tmpl, httpHandlerWrapper(impl, method, tmpl, options))
+	}
+
+	for _, stream := range desc.Streams {
+		tmpl := parsePath(stream.HttpPath)
+		if stream.ClientStreams {
+			// the WebSocket handshake is always a GET request.
+			rs.add(http.MethodGet, tmpl, httpWebSocketHandlerWrapper(impl, stream, tmpl, options))
+			continue
 		}
+
+		rs.add(stream.HttpMethod, tmpl, httpStreamHandlerWrapper(impl, stream, tmpl, options))
 	}
 
+	rs.register(router)
 	return router
 }
 
-func RegisterService(desc *ServiceDescriptor, impl interface{}) http.Handler {
+func parsePath(httpPath string) *httprule.Template {
+	tmpl, err := httprule.Parse(httpPath)
+	if err != nil {
+		panic("pot: RegisterService found invalid HTTP path: " + err.Error())
+	}
+
+	return tmpl
+}
+
+// routes collects the handlers of a service by method and router pattern.
+// Templates differing only by the verb following a "**" variable share a
+// pattern, so their route checks the verb of the request instead.
+type routes []*route
+
+// route is a method and pattern of the router, served by the handler of the
+// template matching the request.
+type route struct {
+	httpMethod string
+	pattern    string
+	handlers   []templateHandler
+}
+
+type templateHandler struct {
+	tmpl    *httprule.Template
+	handler http.HandlerFunc
+}
+
+// add routes httpMethod on the pattern of tmpl. Two templates of a method that
+// cannot be told apart, such as "/v1/{name}" and "/v1/{id}", are rejected.
+func (rs *routes) add(httpMethod string, tmpl *httprule.Template, handler http.HandlerFunc) {
+	if !isToken(httpMethod) {
+		panic("pot: RegisterService found invalid HTTP method: " + httpMethod)
+	}
+
+	pattern := tmpl.RoutePattern()
+	for _, rt := range *rs {
+		if rt.httpMethod != httpMethod || rt.pattern != pattern {
+			continue
+		}
+
+		for _, h := range rt.handlers {
+			if h.tmpl.Verb == tmpl.Verb {
+				panic(fmt.Sprintf("pot: RegisterService found conflicting HTTP paths: %s %s and %s", httpMethod, h.tmpl, tmpl))
+			}
+		}
+
+		rt.handlers = append(rt.handlers, templateHandler{tmpl: tmpl, handler: handler})
+		return
+	}
+
+	*rs = append(*rs, &route{
+		httpMethod: httpMethod,
+		pattern:    pattern,
+		handlers:   []templateHandler{{tmpl: tmpl, handler: handler}},
+	})
+}
+
+// register adds the routes to router. Methods other than the ones chi knows
+// about, such as the kind of a custom rule, are registered with chi first.
+func (rs routes) register(router chi.Router) {
+	for _, rt := range rs {
+		chi.RegisterMethod(rt.httpMethod)
+		if len(rt.handlers) == 1 {
+			router.Method(rt.httpMethod, rt.pattern, rt.handlers[0].handler)
+			continue
+		}
+
+		router.Method(rt.httpMethod, rt.pattern, http.HandlerFunc(rt.serveHTTP))
+	}
+}
+
+// serveHTTP calls the handler of the template whose verb ends the request
+// path, the template without verb matching any other path.
+func (rt *route) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fallback := rt.handlers[0].handler
+	for _, h := range rt.handlers {
+		if h.tmpl.Verb == "" {
+			fallback = h.handler
+			continue
+		}
+
+		if _, ok := h.tmpl.Extract(pathParam(r)); ok {
+			h.handler(w, r)
+			return
+		}
+	}
+
+	// the fallback reports a path matching none of the templates.
+	fallback(w, r)
+}
+
+// isToken reports whether method is a valid RFC 9110 method token.
+func isToken(method string) bool {
+	if method == "" {
+		return false
+	}
+
+	for _, c := range method {
+		if c > unicode.MaxASCII || !(unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func RegisterService(desc *ServiceDescriptor, impl interface{}, opts ...ServerOption) http.Handler {
 	router := chi.NewRouter()
-	return RegisterServiceWithChi(desc, impl, router)
+	return RegisterServiceWithChi(desc, impl, router, opts...)
 }
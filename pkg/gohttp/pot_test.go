@@ -0,0 +1,77 @@
+package gohttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echoMethod replies with prefix followed by the name bound from the path.
+func echoMethod(httpPath, prefix string) MethodDescriptor {
+	return MethodDescriptor{
+		MethodName: prefix,
+		HttpMethod: http.MethodGet,
+		HttpPath:   httpPath,
+		Handler: func(ctx context.Context, srv interface{}, dec DecoderFunc, middleware MiddlewareFunc) (interface{}, error) {
+			in := new(descriptorpb.FileDescriptorProto)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			return wrapperspb.String(prefix + ":" + in.GetName()), nil
+		},
+	}
+}
+
+func TestRegisterServiceVerbs(t *testing.T) {
+	h := RegisterService(&ServiceDescriptor{
+		ServiceName: "test.Files",
+		Methods: []MethodDescriptor{
+			echoMethod("/v1/files/{name=**}", "get"),
+			echoMethod("/v1/files/{name=**}:download", "download"),
+			echoMethod("/v1/files/{name=**}:stat", "stat"),
+		},
+	}, nil)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/v1/files/a/b.txt", want: `"get:a/b.txt"`},
+		{path: "/v1/files/a/b.txt:download", want: `"download:a/b.txt"`},
+		{path: "/v1/files/a:stat", want: `"stat:a"`},
+		{path: "/v1/files/a:other", want: `"get:a:other"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			body, _ := io.ReadAll(rec.Body)
+			if rec.Code != http.StatusOK || strings.TrimSpace(string(body)) != tt.want {
+				t.Fatalf("got %d %s, want %s", rec.Code, body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterServiceConflict(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "conflicting HTTP paths") {
+			t.Fatalf("got %v, want a conflict panic", r)
+		}
+	}()
+
+	RegisterService(&ServiceDescriptor{
+		ServiceName: "test.Files",
+		Methods: []MethodDescriptor{
+			echoMethod("/v1/files/{name}", "name"),
+			echoMethod("/v1/files/{id}", "id"),
+		},
+	}, nil)
+}
@@ -0,0 +1,226 @@
+package gohttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder"
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type (
+	// UnaryServerInfo describes the method a UnaryServerInterceptor is called
+	// for, mirroring grpc.UnaryServerInfo.
+	UnaryServerInfo struct {
+		Server    interface{}
+		Operation string // /helloworld.Greeter/SayHello
+	}
+
+	// UnaryServerInterceptor intercepts unary methods, mirroring
+	// grpc.UnaryServerInterceptor. It is called with the decoded request and
+	// returns the response by calling handler or on its own.
+	UnaryServerInterceptor func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler HandlerFunc) (interface{}, error)
+
+	// ErrorEncoder converts an error returned by a method into the status code
+	// and the body of the response reporting it. Proto message bodies are
+	// encoded with protojson.
+	ErrorEncoder func(ctx context.Context, err error) (int, interface{})
+)
+
+type ServerOptions struct {
+	UnaryInterceptors []UnaryServerInterceptor
+	ErrorEncoder      ErrorEncoder
+
+	JSONMarshalOptions   *protojson.MarshalOptions
+	JSONUnmarshalOptions *protojson.UnmarshalOptions
+
+	MaxFormMemory int64
+	MaxFormSize   int64
+
+	WebSocketUpgrader *websocket.Upgrader
+}
+
+type ServerOption func(*ServerOptions)
+
+func NewServerOptions(options ...ServerOption) *ServerOptions {
+	o := ServerOptions{
+		ErrorEncoder: DefaultErrorEncoder,
+	}
+
+	for _, option := range options {
+		option(&o)
+	}
+
+	return &o
+}
+
+// WithUnaryInterceptor appends an interceptor to the unary chain, the first
+// one being the outermost.
+func WithUnaryInterceptor(interceptor UnaryServerInterceptor) ServerOption {
+	return func(o *ServerOptions) {
+		o.UnaryInterceptors = append(o.UnaryInterceptors, interceptor)
+	}
+}
+
+func WithChainUnaryInterceptor(interceptors ...UnaryServerInterceptor) ServerOption {
+	return func(o *ServerOptions) {
+		o.UnaryInterceptors = append(o.UnaryInterceptors, interceptors...)
+	}
+}
+
+// WithErrorEncoder reports the errors of the service with encoder,
+// DefaultErrorEncoder by default.
+func WithErrorEncoder(encoder ErrorEncoder) ServerOption {
+	if encoder == nil {
+		panic("pot: WithErrorEncoder called with a nil encoder")
+	}
+
+	return func(o *ServerOptions) {
+		o.ErrorEncoder = encoder
+	}
+}
+
+// WithJSONMarshalOptions encodes the JSON replies and stream messages of the
+// service with opts instead of the options of the registered JSON codec.
+func WithJSONMarshalOptions(opts protojson.MarshalOptions) ServerOption {
+	return func(o *ServerOptions) {
+		o.JSONMarshalOptions = &opts
+	}
+}
+
+// WithJSONUnmarshalOptions decodes the JSON requests of the service with opts
+// instead of the options of the registered JSON codec.
+func WithJSONUnmarshalOptions(opts protojson.UnmarshalOptions) ServerOption {
+	return func(o *ServerOptions) {
+		o.JSONUnmarshalOptions = &opts
+	}
+}
+
+// WithMaxFormMemory sets the size of the multipart form parts of the requests
+// of the service decoded in memory, option.DefaultMaxFormMemory by default.
+func WithMaxFormMemory(maxMemory int64) ServerOption {
+	return func(o *ServerOptions) {
+		o.MaxFormMemory = maxMemory
+	}
+}
+
+// WithMaxFormSize rejects the form requests of the service larger than
+// maxSize with 413 Request Entity Too Large, option.DefaultMaxFormSize by
+// default. A negative maxSize lifts the limit.
+func WithMaxFormSize(maxSize int64) ServerOption {
+	return func(o *ServerOptions) {
+		o.MaxFormSize = maxSize
+	}
+}
+
+// WithWebSocketUpgrader upgrades the requests of the client- and
+// bidi-streaming methods of the service with upgrader. The default upgrader
+// rejects cross-origin handshakes, set its CheckOrigin to accept browser
+// clients of other origins.
+func WithWebSocketUpgrader(upgrader *websocket.Upgrader) ServerOption {
+	return func(o *ServerOptions) {
+		o.WebSocketUpgrader = upgrader
+	}
+}
+
+// DefaultErrorEncoder reports an errors.Error with the message of the wrapping
+// errors, a grpc status error as the equivalent errors.Error and any other
+// error as its message. The internal message of an errors.Error is not sent.
+func DefaultErrorEncoder(_ context.Context, err error) (int, interface{}) {
+	type ErrResp struct {
+		Message string `json:"message"`
+	}
+
+	statusCode, err := potErrors.ParseErr(err)
+
+	potErr := &potErrors.Error{}
+	if errors.As(err, &potErr) {
+		resp := *potErr
+		resp.Message = strings.Replace(err.Error(), potErr.Error(), potErr.Message, 1)
+		resp.InternalMessage = ""
+		return statusCode, &resp
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return statusCode, potErrors.FromStatus(s.Proto())
+	}
+
+	return statusCode, ErrResp{Message: err.Error()}
+}
+
+// StatusErrorEncoder reports errors as google.rpc.Status, the body used by
+// grpc-gateway and Connect.
+func StatusErrorEncoder(_ context.Context, err error) (int, interface{}) {
+	statusCode, err := potErrors.ParseErr(err)
+	return statusCode, potErrors.ToStatus(err)
+}
+
+func (o *ServerOptions) encodeError(rw http.ResponseWriter, r *http.Request, err error) {
+	statusCode, body := o.ErrorEncoder(r.Context(), err)
+
+	// a RetryInfo detail is also sent as Retry-After, which clients honor.
+	var retryInfo *potErrors.RetryInfo
+	if errors.As(err, &retryInfo) && retryInfo.RetryDelay > 0 {
+		seconds := (retryInfo.RetryDelay + time.Second - 1) / time.Second
+		rw.Header().Set(option.RetryAfterHeader, strconv.FormatInt(int64(seconds), 10))
+	}
+
+	binder.NewResponseEncoder(rw).BindError(statusCode, body)
+}
+
+// binderOptions returns the options of the service applying to every
+// request, followed by opts.
+func (o *ServerOptions) binderOptions(opts ...option.BinderOption) []option.BinderOption {
+	var binderOpts []option.BinderOption
+	if o.JSONMarshalOptions != nil {
+		binderOpts = append(binderOpts, option.WithJSONMarshalOptions(*o.JSONMarshalOptions))
+	}
+	if o.JSONUnmarshalOptions != nil {
+		binderOpts = append(binderOpts, option.WithJSONUnmarshalOptions(*o.JSONUnmarshalOptions))
+	}
+	if o.MaxFormMemory != 0 {
+		binderOpts = append(binderOpts, option.WithMaxFormMemory(o.MaxFormMemory))
+	}
+	if o.MaxFormSize != 0 {
+		binderOpts = append(binderOpts, option.WithMaxFormSize(o.MaxFormSize))
+	}
+
+	return append(binderOpts, opts...)
+}
+
+// webSocketUpgrader returns the upgrader of the service, a default one
+// accepting same-origin handshakes when it is unset.
+func (o *ServerOptions) webSocketUpgrader() *websocket.Upgrader {
+	if o.WebSocketUpgrader == nil {
+		return &websocket.Upgrader{}
+	}
+
+	return o.WebSocketUpgrader
+}
+
+// middleware chains the unary interceptors of o around the handler of a
+// method, returning nil when there are none.
+func (o *ServerOptions) middleware(info *UnaryServerInfo) MiddlewareFunc {
+	if len(o.UnaryInterceptors) == 0 {
+		return nil
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		for i := len(o.UnaryInterceptors) - 1; i >= 0; i-- {
+			interceptor, handler := o.UnaryInterceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, handler)
+			}
+		}
+
+		return next
+	}
+}
@@ -0,0 +1,309 @@
+package gohttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestUnaryInterceptors(t *testing.T) {
+	var calls []string
+	record := func(name string) UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler HandlerFunc) (interface{}, error) {
+			calls = append(calls, name+" "+info.Operation+" "+req.(*wrapperspb.StringValue).GetValue())
+			defer func() { calls = append(calls, "after "+name) }()
+			return handler(ctx, req)
+		}
+	}
+	deny := func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler HandlerFunc) (interface{}, error) {
+		return nil, fmt.Errorf("denied, %w", potErrors.ErrGeneralForbidden)
+	}
+
+	// handler follows the generated code, calling the server through middleware.
+	handler := func(ctx context.Context, srv interface{}, dec DecoderFunc, middleware MiddlewareFunc) (interface{}, error) {
+		in := new(wrapperspb.StringValue)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return wrapperspb.String("hello " + req.(*wrapperspb.StringValue).GetValue()), nil
+		}
+		if middleware == nil {
+			return call(ctx, in)
+		}
+		return middleware(call)(ctx, in)
+	}
+	desc := &ServiceDescriptor{
+		ServiceName: "test.Greeter",
+		Methods: []MethodDescriptor{{
+			MethodName: "SayHello",
+			Operation:  "/test.Greeter/SayHello",
+			HttpMethod: http.MethodGet,
+			HttpPath:   "/v1/hello/{value}",
+			Handler:    handler,
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		opts      []ServerOption
+		wantCode  int
+		wantCalls []string
+	}{
+		{
+			name:      "no interceptor",
+			wantCode:  http.StatusOK,
+			wantCalls: []string{"handler"},
+		},
+		{
+			name:     "first is outermost",
+			opts:     []ServerOption{WithUnaryInterceptor(record("a")), WithChainUnaryInterceptor(record("b"), record("c"))},
+			wantCode: http.StatusOK,
+			wantCalls: []string{
+				"a /test.Greeter/SayHello x", "b /test.Greeter/SayHello x", "c /test.Greeter/SayHello x",
+				"handler", "after c", "after b", "after a",
+			},
+		},
+		{
+			name:      "short circuit",
+			opts:      []ServerOption{WithUnaryInterceptor(record("a")), WithUnaryInterceptor(deny), WithUnaryInterceptor(record("b"))},
+			wantCode:  http.StatusForbidden,
+			wantCalls: []string{"a /test.Greeter/SayHello x", "after a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			h := RegisterServiceWithChi(desc, nil, chi.NewRouter(), tt.opts...)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/hello/x", nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantCode == http.StatusOK && strings.TrimSpace(rec.Body.String()) != `"hello x"` {
+				t.Fatalf("got body %s", rec.Body)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Fatalf("got calls %q, want %q", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResponseContentNegotiation(t *testing.T) {
+	h := RegisterServiceWithChi(&ServiceDescriptor{
+		ServiceName: "test.Greeter",
+		Methods: []MethodDescriptor{{
+			MethodName: "SayHello",
+			HttpMethod: http.MethodGet,
+			HttpPath:   "/v1/hello/{value}",
+			Handler: func(ctx context.Context, srv interface{}, dec DecoderFunc, middleware MiddlewareFunc) (interface{}, error) {
+				in := new(wrapperspb.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return wrapperspb.String("hello " + in.GetValue()), nil
+			},
+		}},
+	}, nil, chi.NewRouter())
+
+	want, _ := proto.Marshal(wrapperspb.String("hello x"))
+	tests := []struct {
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{accept: "", wantContentType: "application/json", wantBody: `"hello x"`},
+		{accept: "application/x-protobuf", wantContentType: "application/x-protobuf", wantBody: string(want)},
+		{accept: "application/json;q=0.5, application/proto", wantContentType: "application/proto", wantBody: string(want)},
+		{accept: "text/html", wantContentType: "application/json", wantBody: `"hello x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/hello/x", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Fatalf("got content type %s, want %s", got, tt.wantContentType)
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Fatalf("got body %q, want %q", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServerJSONOptions(t *testing.T) {
+	desc := &ServiceDescriptor{
+		ServiceName: "test.Fields",
+		Methods: []MethodDescriptor{{
+			MethodName: "Echo",
+			HttpMethod: http.MethodPost,
+			HttpPath:   "/v1/fields:echo",
+			Body:       "*",
+			Handler: func(ctx context.Context, srv interface{}, dec DecoderFunc, middleware MiddlewareFunc) (interface{}, error) {
+				in := new(descriptorpb.FieldDescriptorProto)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return in, nil
+			},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ServerOption
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "default options",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "service options",
+			opts: []ServerOption{
+				WithJSONMarshalOptions(protojson.MarshalOptions{UseProtoNames: true}),
+				WithJSONUnmarshalOptions(protojson.UnmarshalOptions{DiscardUnknown: true}),
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"type_name":".test.M"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := RegisterServiceWithChi(desc, nil, chi.NewRouter(), tt.opts...)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/fields:echo", strings.NewReader(`{"typeName":".test.M","unknown":1}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantBody != "" && strings.ReplaceAll(rec.Body.String(), " ", "") != tt.wantBody {
+				t.Fatalf("got body %s, want %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRequestBodyNotAllowed(t *testing.T) {
+	h := RegisterServiceWithChi(&ServiceDescriptor{
+		ServiceName: "test.Greeter",
+		Methods: []MethodDescriptor{{
+			MethodName: "SayHello",
+			HttpMethod: http.MethodPost,
+			HttpPath:   "/v1/hello/{value}",
+			Handler: func(ctx context.Context, srv interface{}, dec DecoderFunc, middleware MiddlewareFunc) (interface{}, error) {
+				in := new(wrapperspb.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return wrapperspb.String("hello " + in.GetValue()), nil
+			},
+		}},
+	}, nil, chi.NewRouter())
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "without body", wantCode: http.StatusOK},
+		{name: "with body", body: `"y"`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/hello/x", strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorEncoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "wrapped general error",
+			err:      fmt.Errorf("create book, %w", potErrors.ErrGeneralConflict),
+			wantCode: http.StatusConflict,
+			wantBody: `{"data":null,"message":"create book, general error, Conflict"}`,
+		},
+		{
+			name:     "internal message",
+			err:      fmt.Errorf("get book, %w", potErrors.New("book not found").WithInternalMessage("row 42 missing")),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"data":null,"message":"get book, book not found"}`,
+		},
+		{
+			name:     "grpc status error",
+			err:      status.Error(codes.NotFound, "no book"),
+			wantCode: http.StatusNotFound,
+			wantBody: `{"data":null,"message":"no book","code":5}`,
+		},
+		{
+			name:     "plain error",
+			err:      fmt.Errorf("boom"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"message":"boom"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := DefaultErrorEncoder(context.Background(), tt.err)
+			if code != tt.wantCode {
+				t.Fatalf("got status %d, want %d", code, tt.wantCode)
+			}
+			got, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.wantBody {
+				t.Fatalf("got body %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWithErrorEncoderNil(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("got no panic, want a nil encoder to be rejected")
+		}
+	}()
+
+	WithErrorEncoder(nil)
+}
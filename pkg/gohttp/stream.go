@@ -0,0 +1,155 @@
+package gohttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder"
+	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httprule"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"github.com/gorilla/websocket"
+)
+
+type (
+	// ServerStream is the server side of a streaming method, mirroring
+	// grpc.ServerStream.
+	ServerStream interface {
+		Context() context.Context
+		SendMsg(m interface{}) error
+		RecvMsg(m interface{}) error
+	}
+
+	StreamHandlerFunc func(srv interface{}, stream ServerStream) error
+
+	StreamDescriptor struct {
+		StreamName string
+
+		HttpMethod    string
+		HttpPath      string
+		Body          string
+		ResponseBody  string
+		Handler       StreamHandlerFunc
+		ServerStreams bool
+		ClientStreams bool
+	}
+)
+
+// eventStream serves a server-streaming method as server-sent events or
+// newline-delimited JSON. The request is decoded once from the HTTP request.
+type eventStream struct {
+	ctx     context.Context
+	dec     DecoderFunc
+	enc     *binder.StreamEncoder
+	decoded bool
+}
+
+func (s *eventStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *eventStream) SendMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.enc.Send(m)
+}
+
+func (s *eventStream) RecvMsg(m interface{}) error {
+	if s.decoded {
+		return io.EOF
+	}
+	s.decoded = true
+
+	return s.dec(m)
+}
+
+func httpStreamHandlerWrapper(impl interface{}, stream StreamDescriptor, tmpl *httprule.Template, opts *ServerOptions) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if err := bindPathVars(r, tmpl); err != nil {
+			opts.encodeError(rw, r, err)
+			return
+		}
+
+		ss := &eventStream{
+			ctx: r.Context(),
+			dec: NewDecoderFunc(r, opts.binderOptions(option.WithPathTemplate(stream.HttpPath), option.WithBody(stream.Body))...),
+			enc: binder.NewStreamEncoder(rw, r, opts.binderOptions(option.WithResponseBody(stream.ResponseBody))...),
+		}
+
+		err := stream.Handler(impl, ss)
+		if err == nil {
+			ss.enc.Finish()
+			return
+		}
+
+		if !ss.enc.Started() {
+			opts.encodeError(rw, r, err)
+			return
+		}
+
+		_, body := opts.ErrorEncoder(ss.ctx, err)
+		if err := ss.enc.SendError(body); errors.Is(err, binder.ErrRawStream) {
+			// abort the connection so that the client does not mistake the
+			// truncated body for a complete one.
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// webSocketStream serves a client- or bidi-streaming method over a WebSocket.
+type webSocketStream struct {
+	ctx  context.Context
+	conn *binder.WebSocketServerConn
+}
+
+func (s *webSocketStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *webSocketStream) SendMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.conn.SendMsg(m)
+}
+
+func (s *webSocketStream) RecvMsg(m interface{}) error {
+	return s.conn.RecvMsg(m)
+}
+
+func httpWebSocketHandlerWrapper(impl interface{}, stream StreamDescriptor, tmpl *httprule.Template, opts *ServerOptions) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if err := bindPathVars(r, tmpl); err != nil {
+			opts.encodeError(rw, r, err)
+			return
+		}
+
+		if !websocket.IsWebSocketUpgrade(r) {
+			opts.encodeError(rw, r, fmt.Errorf("websocket upgrade required, %w", potErrors.ErrGeneralBadRequest))
+			return
+		}
+
+		conn, err := opts.webSocketUpgrader().Upgrade(rw, r, nil)
+		if err != nil {
+			// the upgrader already replied with an HTTP error.
+			return
+		}
+
+		ss := &webSocketStream{
+			ctx:  r.Context(),
+			conn: binder.NewWebSocketServerConn(conn, opts.binderOptions(option.WithResponseBody(stream.ResponseBody))...),
+		}
+		defer ss.conn.Close()
+
+		if err := stream.Handler(impl, ss); err != nil {
+			_, body := opts.ErrorEncoder(ss.ctx, err)
+			ss.conn.SendError(body)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package gohttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestServerStream(t *testing.T) {
+	h := RegisterService(&ServiceDescriptor{
+		ServiceName: "test.Watcher",
+		Streams: []StreamDescriptor{{
+			StreamName:    "Watch",
+			HttpMethod:    http.MethodGet,
+			HttpPath:      "/v1/watch/{value}",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream ServerStream) error {
+				in := new(wrapperspb.StringValue)
+				if err := stream.RecvMsg(in); err != nil {
+					return err
+				}
+				for _, s := range strings.Split(in.GetValue(), ",") {
+					if s == "" {
+						continue
+					}
+					if err := stream.SendMsg(wrapperspb.String(s)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}},
+	}, nil)
+
+	tests := []struct {
+		name   string
+		value  string
+		accept option.ContentType
+		want   []string
+	}{
+		{name: "events", value: "a,b", accept: option.ContentTypeEventStream, want: []string{"a", "b"}},
+		{name: "ndjson", value: "a,b", accept: option.ContentTypeNDJSON, want: []string{"a", "b"}},
+		{name: "empty events", value: ",", accept: option.ContentTypeEventStream},
+		{name: "empty ndjson", value: ",", accept: option.ContentTypeNDJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/watch/"+tt.value, nil)
+			req.Header.Set(option.AcceptHeader, tt.accept.String())
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			dec := binder.NewStreamDecoder(rec.Result())
+			var got []string
+			for {
+				m := new(wrapperspb.StringValue)
+				err := dec.RecvMsg(m)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, m.GetValue())
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderEmptyBody(t *testing.T) {
+	dec := binder.NewStreamDecoder(&http.Response{Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))})
+	if err := dec.RecvMsg(new(wrapperspb.StringValue)); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestStreamDecoderEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "terminated events", body: "data: \"a\"\n\ndata: \"b\"\n\n", want: []string{"a", "b"}},
+		{name: "keep-alive", body: ": ping\n\ndata: \"a\"\n\n", want: []string{"a"}},
+		{name: "event cut after its data", body: "data: \"a\"\n\ndata: \"b\"\n", want: []string{"a", "b"}},
+		{name: "event cut in its data", body: "data: \"a\"\n\ndata: \"b\"", want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{option.ContentTypeHeader: []string{option.ContentTypeEventStream.String()}}
+			dec := binder.NewStreamDecoder(&http.Response{Header: header, Body: io.NopCloser(strings.NewReader(tt.body))})
+
+			var got []string
+			for {
+				m := new(wrapperspb.StringValue)
+				err := dec.RecvMsg(m)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, m.GetValue())
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketUpgrader(t *testing.T) {
+	desc := &ServiceDescriptor{
+		ServiceName: "test.Chat",
+		Streams: []StreamDescriptor{{
+			StreamName:    "Chat",
+			HttpPath:      "/v1/chat",
+			ClientStreams: true,
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream ServerStream) error {
+				return nil
+			},
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		opts   []ServerOption
+		origin string
+		want   int
+	}{
+		{name: "same origin", origin: "http://example.com", want: http.StatusSwitchingProtocols},
+		{name: "cross origin", origin: "http://other.com", want: http.StatusForbidden},
+		{
+			name:   "cross origin allowed",
+			opts:   []ServerOption{WithWebSocketUpgrader(&websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }})},
+			origin: "http://other.com",
+			want:   http.StatusSwitchingProtocols,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := RegisterService(desc, nil, tt.opts...)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Host = "example.com"
+				h.ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+
+			header := http.Header{"Origin": {tt.origin}}
+			conn, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/chat", header)
+			if conn != nil {
+				conn.Close()
+			}
+			if res == nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.want {
+				t.Fatalf("got %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Host is the host of the requests served by a HandlerTransport when the
+// client sets no base URL.
+const Host = "in-process"
+
+// HandlerTransport is an http.RoundTripper serving requests with an
+// http.Handler in memory. Each request runs over an in-memory connection to an
+// http.Server, so requests, responses, streams and websocket upgrades go
+// through the same encoding as over the network without opening a socket.
+// Requests must use the http scheme.
+type HandlerTransport struct {
+	server    *http.Server
+	transport *http.Transport
+}
+
+var _ http.RoundTripper = (*HandlerTransport)(nil)
+
+func NewHandlerTransport(handler http.Handler) *HandlerTransport {
+	t := &HandlerTransport{
+		server: &http.Server{Handler: handler},
+	}
+	// connections are not reused, so that none outlives its request.
+	t.transport = &http.Transport{
+		DialContext:       t.DialContext,
+		DisableKeepAlives: true,
+	}
+
+	return t
+}
+
+func (t *HandlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(req)
+}
+
+// DialContext opens an in-memory connection to the handler, whatever the
+// address. It dials the websockets of the handler.
+func (t *HandlerTransport) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, server := net.Pipe()
+	l := &pipeListener{
+		conns: make(chan net.Conn, 1),
+		done:  make(chan struct{}),
+	}
+	l.conns <- &pipeConn{Conn: server, close: l.close}
+
+	go func() {
+		// Serve returns once the connection is closed, or right away when the
+		// transport is closed.
+		_ = t.server.Serve(l)
+		server.Close()
+	}()
+
+	return client, nil
+}
+
+// Close closes the connections still being served.
+func (t *HandlerTransport) Close() error {
+	return t.server.Close()
+}
+
+// pipeListener accepts a single connection, then blocks until it is closed.
+type pipeListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.close()
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+func (l *pipeListener) close() {
+	l.once.Do(func() { close(l.done) })
+}
+
+// pipeConn closes its listener along with the connection.
+type pipeConn struct {
+	net.Conn
+	close func()
+}
+
+func (c *pipeConn) Close() error {
+	c.close()
+	return c.Conn.Close()
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return Host }
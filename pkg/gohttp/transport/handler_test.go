@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestHandlerTransportRoundTrip(t *testing.T) {
+	tr := NewHandlerTransport(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rw.Header().Set("X-Method", r.Method)
+		fmt.Fprintf(rw, "%s %s %s", r.Host, r.URL.RequestURI(), body)
+	}))
+	defer tr.Close()
+
+	client := &http.Client{Transport: tr}
+	for i := 0; i < 2; i++ {
+		res, err := client.Post("http://"+Host+"/v1/books?page=2", "text/plain", strings.NewReader("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if want := Host + " /v1/books?page=2 hello"; string(body) != want || res.Header.Get("X-Method") != http.MethodPost {
+			t.Fatalf("got %s %q, want %q", res.Header.Get("X-Method"), body, want)
+		}
+	}
+}
+
+func TestHandlerTransportStream(t *testing.T) {
+	next := make(chan struct{})
+	tr := NewHandlerTransport(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(rw, "event %d\n", i)
+			rw.(http.Flusher).Flush()
+			<-next
+		}
+	}))
+	defer tr.Close()
+
+	res, err := (&http.Client{Transport: tr}).Get("http://" + Host + "/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	// each event is read before the handler writes the next one.
+	lines := bufio.NewReader(res.Body)
+	for i := 0; i < 3; i++ {
+		line, err := lines.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("event %d\n", i); line != want {
+			t.Fatalf("got %q, want %q", line, want)
+		}
+		next <- struct{}{}
+	}
+	if _, err := lines.ReadString('\n'); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestHandlerTransportWebSocket(t *testing.T) {
+	tr := NewHandlerTransport(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(rw, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(typ, append([]byte("echo "), msg...))
+		}
+	}))
+	defer tr.Close()
+
+	dialer := &websocket.Dialer{NetDialContext: tr.DialContext}
+	conn, _, err := dialer.DialContext(context.Background(), "ws://"+Host+"/v1/chat", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, msg := range []string{"a", "b"} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		_, got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "echo " + msg; string(got) != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestHandlerTransportClose(t *testing.T) {
+	tr := NewHandlerTransport(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+
+	res, err := (&http.Client{Transport: tr}).Get("http://" + Host + "/v1/wait")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	// closing the transport ends the requests still being served.
+	tr.Close()
+	if _, err := io.ReadAll(res.Body); err == nil {
+		t.Fatal("got the whole body, want an error")
+	}
+}